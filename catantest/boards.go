@@ -0,0 +1,51 @@
+// boards.go
+
+// Boards whose tiles a test can count on
+package catantest
+
+import "catango/gameplay"
+
+// Flower is seven tiles, a lumber 6 with a ring around it, so every resource
+// is on the board and the centre's corners each touch three tiles:
+//
+//	    O 9     S 10
+//	D       L 6     B 8
+//	    S 4     W 5
+//
+// Tiles are numbered from 1 in reading order, as listed: O 9, S 10, the
+// desert with the robber, L 6, B 8, S 4, W 5.
+func Flower() *gameplay.BoardFile {
+	return &gameplay.BoardFile{
+		Name: "flower",
+		Tiles: []gameplay.TileEntry{
+			{Q: 0, R: -1, Resource: "O", Number: 9},
+			{Q: 1, R: -1, Resource: "S", Number: 10},
+			{Q: -1, R: 0, Resource: "D"},
+			{Q: 0, R: 0, Resource: "L", Number: 6},
+			{Q: 1, R: 0, Resource: "B", Number: 8},
+			{Q: -1, R: 1, Resource: "S", Number: 4},
+			{Q: 0, R: 1, Resource: "W", Number: 5},
+		},
+	}
+}
+
+// Corner is the vertex the tiles all share, failing the test if there is
+// none
+func (b *Builder) Corner(tiles ...gameplay.TileID) int {
+	b.t.Helper()
+	for id := 1; id <= b.Game.Board.Graph.VertexCount(); id++ {
+		shared := 0
+		for _, tile := range gameplay.GetVertexByID(b.Game, id).TileIds {
+			for _, wanted := range tiles {
+				if tile == wanted {
+					shared++
+				}
+			}
+		}
+		if shared == len(tiles) {
+			return id
+		}
+	}
+	b.t.Fatalf("tiles %v share no corner", tiles)
+	return 0
+}
@@ -0,0 +1,203 @@
+// catantest.go
+
+// Package catantest helps test code built on the gameplay package: games
+// set up as a test needs them, boards whose tiles are known, dice that roll
+// what the test says, and scripted input for the CLI. Every helper takes
+// the test and fails it on a step the game refuses, so tests read as a list
+// of steps.
+package catantest
+
+import (
+	"catango/gameplay"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Builder holds a game being set up for a test
+type Builder struct {
+	t    testing.TB
+	Game *gameplay.CatanGame
+}
+
+// NewGame starts a game for players 1 to count on the board a board file
+// describes, in the setup phase with nothing placed. The bank and
+// development cards are shuffled with seed 1.
+func NewGame(t testing.TB, file *gameplay.BoardFile, count int) *Builder {
+	t.Helper()
+	game, err := gameplay.NewCatanGameFromFile(playerIDs(t, count), file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Builder{t: t, Game: game}
+}
+
+// Beginner starts a game on the standard beginner board, see BoardSpecNamed
+func Beginner(t testing.TB, count int) *Builder {
+	t.Helper()
+	spec, err := gameplay.BoardSpecNamed("beginner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count < spec.MinPlayers || count > spec.MaxPlayers {
+		t.Fatalf("the beginner board is for %d to %d players, not %d", spec.MinPlayers, spec.MaxPlayers, count)
+	}
+	game, err := gameplay.NewCatanGameOnBoard(playerIDs(t, count), spec, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Builder{t: t, Game: game}
+}
+
+// Players 1 to count
+func playerIDs(t testing.TB, count int) []int {
+	t.Helper()
+	if count < gameplay.BaseBoard.MinPlayers || count > gameplay.ExtensionBoard.MaxPlayers {
+		t.Fatalf("a game is for %d to %d players, not %d", gameplay.BaseBoard.MinPlayers, gameplay.ExtensionBoard.MaxPlayers, count)
+	}
+	ids := make([]int, count)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	return ids
+}
+
+// Player is a player of the game by ID
+func (b *Builder) Player(id int) *gameplay.Player {
+	b.t.Helper()
+	player := gameplay.GetPlayerByID(b.Game, id)
+	if player == nil {
+		b.t.Fatalf("there is no player %d", id)
+	}
+	return player
+}
+
+// Settlement places a settlement for free, keeping only the distance rule
+func (b *Builder) Settlement(player, vertex int) *Builder {
+	b.t.Helper()
+	valid := false
+	for _, id := range gameplay.ComputeValidVertexPlacements(b.Game) {
+		valid = valid || id == vertex
+	}
+	if !valid {
+		b.t.Fatalf("vertex %d cannot take a settlement", vertex)
+	}
+	gameplay.PlaceSettlement(vertex, b.Player(player), b.Game)
+	return b
+}
+
+// City turns one of the player's settlements into a city for free
+func (b *Builder) City(player, vertex int) *Builder {
+	b.t.Helper()
+	if owner := gameplay.VertexOwner(b.Game, vertex); owner == nil || owner.ID != player || gameplay.VertexBuilding(b.Game, vertex) != 1 {
+		b.t.Fatalf("player %d has no settlement on vertex %d", player, vertex)
+	}
+	gameplay.PlaceCity(vertex, b.Player(player), b.Game)
+	return b
+}
+
+// Road places a road for free between two vertices
+func (b *Builder) Road(player, from, to int) *Builder {
+	b.t.Helper()
+	if !gameplay.RoadEmptySpace(from, to, b.Game) {
+		b.t.Fatalf("there is no free edge between vertices %d and %d", from, to)
+	}
+	gameplay.PlaceRoad(from, to, b.Player(player), b.Game)
+	return b
+}
+
+// Give moves cards to the player from the bank, written like "2B 1W O"
+func (b *Builder) Give(player int, cards string) *Builder {
+	b.t.Helper()
+	for resource, amount := range Cards(b.t, cards) {
+		if !gameplay.BankToPlayerResource(b.Game, b.Player(player), resource, amount) {
+			b.t.Fatalf("the bank has fewer than %d %s", amount, resource)
+		}
+	}
+	return b
+}
+
+// Turn skips setup, starting the player's turn in the main phase before
+// they roll
+func (b *Builder) Turn(player int) *Builder {
+	b.t.Helper()
+	for i, p := range b.Game.Players {
+		if p.ID == player {
+			b.Game.Phase, b.Game.TurnIndex, b.Game.HasRolled = "main", i, false
+			return b
+		}
+	}
+	b.t.Fatalf("there is no player %d", player)
+	return b
+}
+
+// Do applies an action through the rules engine, rolling the dice given
+func (b *Builder) Do(action gameplay.Action, dice *Dice) *Builder {
+	b.t.Helper()
+	if action.PlayerID == 0 {
+		action.PlayerID = gameplay.CurrentPlayer(b.Game).ID
+	}
+	if err := gameplay.ApplyAction(b.Game, action, dice.Rand()); err != nil {
+		b.t.Fatalf("%s: %v", action, err)
+	}
+	return b
+}
+
+// Roll has the current player roll the dice given
+func (b *Builder) Roll(dice *Dice) *Builder {
+	b.t.Helper()
+	return b.Do(gameplay.Action{Type: gameplay.ActionRoll}, dice)
+}
+
+// Cards reads cards written like "2B 1W O", a count before a resource
+// letter and 1 if there is none
+func Cards(t testing.TB, cards string) map[string]int {
+	t.Helper()
+	hand := make(map[string]int)
+	for _, word := range strings.Fields(cards) {
+		count, resource := 1, word[len(word)-1:]
+		if len(word) > 1 {
+			n, err := strconv.Atoi(word[:len(word)-1])
+			if err != nil {
+				t.Fatalf("%q is not like 2B in %q", word, cards)
+			}
+			count = n
+		}
+		if !isResource(resource) {
+			t.Fatalf("%q is not a resource in %q", resource, cards)
+		}
+		hand[resource] += count
+	}
+	return hand
+}
+
+func isResource(resource string) bool {
+	for _, r := range gameplay.ResourceTypes {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Script reads script lines (see gameplay/script.go) as a CLI game's input
+func Script(t testing.TB, lines ...string) *gameplay.Script {
+	t.Helper()
+	script, err := gameplay.NewScript(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+// CLI is a CLI game reading the script, writing to the returned builder and
+// saving into the test's temporary directory
+func CLI(t testing.TB, script *gameplay.Script) (*gameplay.CLIGame, *strings.Builder) {
+	output := &strings.Builder{}
+	return &gameplay.CLIGame{
+		Input:    script,
+		Output:   output,
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+	}, output
+}
@@ -0,0 +1,249 @@
+package catantest
+
+import (
+	"catango/gameplay"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Flower's tiles, see boards.go
+const (
+	ore9 gameplay.TileID = iota + 1
+	sheep10
+	desert
+	lumber6
+	brick8
+	sheep4
+	wheat5
+)
+
+func TestFlower(t *testing.T) {
+	game := NewGame(t, Flower(), 3).Game
+	expected := map[gameplay.TileID]string{lumber6: "L6", brick8: "B8", wheat5: "W5", sheep4: "S4", desert: "D0", ore9: "O9", sheep10: "S10"}
+	for id, tile := range expected {
+		got := gameplay.GetTileByID(game, id)
+		if got == nil || fmt.Sprintf("%s%d", got.Resource, got.NumberToken) != tile {
+			t.Errorf("tile %d is %+v, expected %s", id, got, tile)
+		}
+	}
+	if game.Board.RobberPosition != desert {
+		t.Errorf("the robber starts on tile %d, expected the desert", game.Board.RobberPosition)
+	}
+}
+
+func TestRolls(t *testing.T) {
+	dice := Rolls(t, 2, 7, 12)
+	rng := dice.Rand()
+	for _, expected := range []int{2, 7, 12} {
+		if roll := rng.Intn(6) + rng.Intn(6) + 2; roll != expected {
+			t.Errorf("rolled %d, expected %d", roll, expected)
+		}
+	}
+	if dice.Left() != 0 {
+		t.Errorf("%d dice are left", dice.Left())
+	}
+}
+
+// A test the helpers fail, keeping the message
+type recorder struct {
+	testing.TB
+	fatal string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// The message a helper fails with, empty if it does not
+func failure(t *testing.T, helper func(tb testing.TB)) string {
+	r := &recorder{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		helper(r)
+	}()
+	<-done
+	return r.fatal
+}
+
+// Arguments no game can have fail the test instead of panicking
+func TestBadArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		helper   func(tb testing.TB)
+		expected string
+	}{
+		{"roll 13", func(tb testing.TB) { Rolls(tb, 6, 13) }, "cannot roll 13"},
+		{"roll 1", func(tb testing.TB) { Rolls(tb, 1) }, "cannot roll 1"},
+		{"5 beginners", func(tb testing.TB) { Beginner(tb, 5) }, "not 5"},
+		{"7 players", func(tb testing.TB) { NewGame(tb, Flower(), 7) }, "not 7"},
+		{"no players", func(tb testing.TB) { NewGame(tb, Flower(), 0) }, "not 0"},
+		{"4 beginners", func(tb testing.TB) { Beginner(tb, 4) }, ""},
+	}
+	for _, test := range tests {
+		got := failure(t, test.helper)
+		if test.expected == "" && got != "" || !strings.Contains(got, test.expected) {
+			t.Errorf("%s failed with %q, expected %q", test.name, got, test.expected)
+		}
+	}
+}
+
+func TestCards(t *testing.T) {
+	if got := Cards(t, "2B W 3O W"); !reflect.DeepEqual(got, map[string]int{"B": 2, "W": 2, "O": 3}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+// Snake order setup through the rules engine, with the second settlement
+// paying out the tiles it touches
+func TestSetup(t *testing.T) {
+	b := NewGame(t, Flower(), 3)
+	game := b.Game
+	gameplay.BeginSetup(game, b.Player(1))
+	second := b.Corner(lumber6, brick8, wheat5)
+
+	// Nobody settles on or next to the corner player 1 saves for last
+	saved := map[int]bool{second: true}
+	for _, id := range gameplay.GetVertexByID(game, second).AdjacentVertexes {
+		saved[id] = true
+	}
+	var order []int
+	for game.Phase == "setup" {
+		legal := gameplay.LegalActions(game)
+		action := legal[0]
+		if game.SetupVertex == 0 {
+			player := gameplay.CurrentPlayer(game)
+			order = append(order, player.ID)
+			for _, a := range legal {
+				if !saved[a.VertexID] {
+					action = a
+					break
+				}
+			}
+			if len(order) == 6 {
+				action = gameplay.Action{Type: gameplay.ActionSetupSettlement, PlayerID: player.ID, VertexID: second}
+			}
+		}
+		b.Do(action, nil)
+	}
+
+	if !reflect.DeepEqual(order, []int{1, 2, 3, 3, 2, 1}) {
+		t.Errorf("placed in order %v, expected the snake 1 2 3 3 2 1", order)
+	}
+	if got := b.Player(1).Resources; got["L"] != 1 || got["B"] != 1 || got["W"] != 1 {
+		t.Errorf("player 1 got %v from their second settlement, expected 1 each of L, B and W", got)
+	}
+	if game.Phase != "main" || gameplay.CurrentPlayer(game).ID != 1 {
+		t.Errorf("after setup the phase is %s with %s to play", game.Phase, gameplay.CurrentPlayer(game))
+	}
+}
+
+// A roll pays every building on a tile with its number, two for a city
+func TestProduction(t *testing.T) {
+	b := NewGame(t, Flower(), 3)
+	b.Settlement(1, b.Corner(lumber6, brick8, wheat5)).
+		Settlement(2, b.Corner(lumber6, sheep4, desert)).City(2, b.Corner(lumber6, sheep4, desert)).
+		Turn(1).Roll(Rolls(t, 6))
+	if got := b.Player(1).Resources["L"]; got != 1 {
+		t.Errorf("player 1 has %d lumber, expected 1 for the settlement", got)
+	}
+	if got := b.Player(2).Resources["L"]; got != 2 {
+		t.Errorf("player 2 has %d lumber, expected 2 for the city", got)
+	}
+
+	b.Turn(2).Roll(Rolls(t, 8))
+	if got := b.Player(1).Resources["B"]; got != 1 {
+		t.Errorf("player 1 has %d brick after an 8, expected 1", got)
+	}
+	if got := b.Player(2).Resources["B"]; got != 0 {
+		t.Errorf("player 2 has %d brick, but is not on the 8", got)
+	}
+}
+
+// Roads, settlements and cities cost what they should and count for points
+func TestBuilding(t *testing.T) {
+	b := NewGame(t, Flower(), 3)
+	corner := b.Corner(lumber6, brick8, wheat5)
+	b.Settlement(1, corner).Turn(1).Roll(Rolls(t, 12))
+
+	// Out two roads to a free corner
+	var path []int
+	for _, next := range gameplay.GetVertexByID(b.Game, corner).AdjacentVertexes {
+		for _, far := range gameplay.GetVertexByID(b.Game, next).AdjacentVertexes {
+			if far != corner && len(path) == 0 {
+				path = []int{corner, next, far}
+			}
+		}
+	}
+	b.Give(1, "2B 2L")
+	for i := 0; i < 2; i++ {
+		b.Do(gameplay.Action{Type: gameplay.ActionBuildRoad, VertexID: min(path[i], path[i+1]), VertexID2: max(path[i], path[i+1])}, nil)
+	}
+	if owner := gameplay.RoadOwner(b.Game, path[1], path[2]); owner == nil || owner.ID != 1 {
+		t.Fatalf("the second road is not player 1's")
+	}
+
+	b.Give(1, "B L S W").Do(gameplay.Action{Type: gameplay.ActionBuildSettlement, VertexID: path[2]}, nil)
+	b.Give(1, "2W 3O").Do(gameplay.Action{Type: gameplay.ActionBuildCity, VertexID: corner}, nil)
+	player := b.Player(1)
+	if player.VictoryPoints != 3 {
+		t.Errorf("player 1 has %d points, expected 3 for a city and a settlement", player.VictoryPoints)
+	}
+	for resource, amount := range player.Resources {
+		if amount != 0 {
+			t.Errorf("player 1 has %d %s left, expected everything spent", amount, resource)
+		}
+	}
+
+	err := gameplay.ApplyAction(b.Game, gameplay.Action{Type: gameplay.ActionBuildCity, PlayerID: 1, VertexID: path[2]}, nil)
+	if err == nil {
+		t.Error("a city was built without the cards for it")
+	}
+}
+
+// Building to the tenth point wins the game
+func TestVictory(t *testing.T) {
+	b := Beginner(t, 3)
+	var last int
+	for i := 0; i < 6; i++ {
+		last = gameplay.ComputeValidVertexPlacements(b.Game)[0]
+		b.Settlement(1, last)
+		if i < 3 {
+			b.City(1, last)
+		}
+	}
+	if points := gameplay.TotalVictoryPoints(b.Player(1)); points != 9 {
+		t.Fatalf("player 1 has %d points, expected 9", points)
+	}
+
+	b.Give(1, "2W 3O").Turn(1).Roll(Rolls(t, 3)).
+		Do(gameplay.Action{Type: gameplay.ActionBuildCity, VertexID: last}, nil)
+	if b.Game.Phase != "finished" || b.Game.WinnerID != 1 {
+		t.Errorf("the phase is %s and the winner %d, expected player 1 to have won", b.Game.Phase, b.Game.WinnerID)
+	}
+}
+
+// A CLI game plays a script, stopping at an expectation that fails
+func TestScript(t *testing.T) {
+	b := NewGame(t, Flower(), 3)
+	b.Settlement(1, b.Corner(lumber6, brick8, wheat5)).Turn(1)
+	cg, output := CLI(t, Script(t, "expect vp 1 = 1", "r", "expect cards 1 = 0", "end", "expect turn = 2"))
+	cg.Rand = Rolls(t, 12).Rand()
+	if err := cg.Play(b.Game); !errors.Is(err, gameplay.ErrInputClosed) {
+		t.Fatalf("got %v, expected the script to run out\n%s", err, output)
+	}
+
+	cg, _ = CLI(t, Script(t, "expect vp 1 = 5", "r"))
+	cg.Rand = rand.New(rand.NewSource(1))
+	if err := cg.Play(b.Game); err == nil || !strings.Contains(err.Error(), "script line 1") {
+		t.Errorf("got %v, expected the first line to fail", err)
+	}
+}
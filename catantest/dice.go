@@ -0,0 +1,63 @@
+// dice.go
+
+// Dice that roll what a test says. The rules engine rolls with a
+// *rand.Rand, taking one number below 6 for each die, so a rand.Source
+// giving those numbers in turn decides the rolls.
+package catantest
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Dice is a source of loaded rolls. Anything else drawn from it, such as
+// the card a robber steals, takes the next die too, as a number below 6
+// taken modulo what is drawn. Once the rolls run out it is seeded random.
+type Dice struct {
+	faces []int
+	rest  rand.Source
+}
+
+// Rolls loads dice with the totals to roll, 2 to 12, split between the two
+// dice with the first as high as it goes
+func Rolls(t testing.TB, totals ...int) *Dice {
+	t.Helper()
+	dice := &Dice{rest: rand.NewSource(1)}
+	for _, total := range totals {
+		if total < 2 || total > 12 {
+			t.Fatalf("two dice cannot roll %d", total)
+		}
+		first := min(6, total-1)
+		dice.faces = append(dice.faces, first, total-first)
+	}
+	return dice
+}
+
+// Rand is the dice as the rules engine takes them, nil for nil dice, which
+// is fine for actions that roll nothing
+func (d *Dice) Rand() *rand.Rand {
+	if d == nil {
+		return nil
+	}
+	return rand.New(d)
+}
+
+// Int63 gives the next face as rand.Rand's Intn(6) reads it: Int31, the top
+// 31 bits, modulo 6
+func (d *Dice) Int63() int64 {
+	if len(d.faces) == 0 {
+		return d.rest.Int63()
+	}
+	face := d.faces[0]
+	d.faces = d.faces[1:]
+	return int64(face-1) << 32
+}
+
+func (d *Dice) Seed(seed int64) {
+	d.rest.Seed(seed)
+}
+
+// Left is how many dice are still loaded
+func (d *Dice) Left() int {
+	return len(d.faces)
+}
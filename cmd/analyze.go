@@ -0,0 +1,83 @@
+package main
+
+import (
+	"catango/gameplay"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// catango analyze: board statistics and the best opening placements
+func runAnalyze(args []string) error {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	players := fs.Int("players", 4, "number of players, which picks the board unless --board is given")
+	board := fs.String("board", "", "board to generate: base, beginner, extension or seafarers")
+	layout := fs.String("layout", "", "how tiles and numbers are laid out: random, spiral or fixed (default the board's own)")
+	fair := fs.Bool("fair", false, "generate a balanced board")
+	boardFile := fs.String("board-file", "", "analyse the board described in this JSON file instead")
+	seed := fs.Int64("seed", 0, "seed for the generated board (default random)")
+	vertices := fs.Int("vertices", 15, "best vertices to list")
+	openings := fs.Int("openings", 10, "best opening pairs to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *vertices < 0 {
+		return fmt.Errorf("--vertices must be at least 0, not %d", *vertices)
+	}
+	if *openings < 0 {
+		return fmt.Errorf("--openings must be at least 0, not %d", *openings)
+	}
+	var boardLayout gameplay.BoardLayout
+	if *layout != "" {
+		var err error
+		if boardLayout, err = gameplay.ParseBoardLayout(*layout); err != nil {
+			return err
+		}
+	}
+
+	var analysed *gameplay.Board
+	if *boardFile != "" {
+		file, err := gameplay.LoadBoardFile(*boardFile)
+		if err != nil {
+			return err
+		}
+		if analysed, err = file.Board(); err != nil {
+			return err
+		}
+		fmt.Printf("Board: %s\n", *boardFile)
+	} else {
+		spec := gameplay.BoardSpecFor(*players)
+		if *board != "" {
+			var err error
+			if spec, err = gameplay.BoardSpecNamed(*board); err != nil {
+				return err
+			}
+		}
+		if *players < spec.MinPlayers || *players > spec.MaxPlayers {
+			return fmt.Errorf("--players must be %d to %d for the %s board, not %d", spec.MinPlayers, spec.MaxPlayers, spec.Name, *players)
+		}
+		if boardLayout != "" {
+			spec.Layout = boardLayout
+		}
+		if *fair {
+			spec.Constraints = gameplay.FairBoard
+		}
+		if *seed == 0 {
+			*seed = time.Now().UnixNano()
+		}
+		ids := make([]int, *players)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		game, err := gameplay.NewCatanGameOnBoard(ids, spec, *seed)
+		if err != nil {
+			return err
+		}
+		analysed = game.Board
+		fmt.Printf("Board: %s, seed %d\n", spec.Name, *seed)
+	}
+
+	gameplay.AnalyzeBoard(analysed, *openings).WriteReport(os.Stdout, *vertices)
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"catango/gameplay"
+	"errors"
+	"flag"
+	"io"
+	"os"
+)
+
+// catango load: carry on a saved game, saving it back where it came from if
+// it is stopped again
+func runLoad(args []string) error {
+	return loadAt(args, os.Stdin, os.Stdout)
+}
+
+// Carries on a saved game reading input and writing output, the terminal's
+// but for tests
+func loadAt(args []string, input io.Reader, output io.Writer) error {
+	fs := flag.NewFlagSet("load", flag.ContinueOnError)
+	session := addSessionFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("which game? catango load save.json")
+	}
+	path := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil { // Flags may follow the file
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("only one game can be loaded")
+	}
+
+	game, seats, err := gameplay.LoadSession(path)
+	if err != nil {
+		return err
+	}
+	if *session.save == "" {
+		*session.save = path
+	}
+	cg, err := session.cliGame(input)
+	if err != nil {
+		return err
+	}
+	cg.Output = output
+	defer cg.Close()
+	if seats != nil {
+		if err := cg.SetSeats(game, seats); err != nil {
+			return err
+		}
+	}
+	return finished(cg, cg.Resume(game))
+}
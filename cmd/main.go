@@ -1,32 +1,48 @@
 package main
 
 import (
-	"catango/gameplay"
 	"fmt"
 	"os"
 	"strings"
 )
 
-func main() {
-	// Check if running in debug mode via env var
-	testMode := os.Getenv("DEBUG_MODE") == "true"
-
-	var cg *gameplay.CLIGame
-	if testMode {
-		input := strings.NewReader("3\n\n\n\n\n\n") // Replace with appropriate test inputs
-		cg = &gameplay.CLIGame{Input: input}
-	} else {
-		cg = &gameplay.CLIGame{Input: os.Stdin}
-	}
+const usage = `Usage: catango [command] [flags]
 
-	playerCount := cg.Initialize()
-	game := cg.BaseGame.Initialize(playerCount)
+Commands:
+  play      play a game at this terminal (the default)
+  load      carry on a saved game: catango load save.json
+  simulate  play bot games headless and report the results
+  analyze   board statistics and the best opening placements
+  serve     play games over TCP, one per connection
 
-	cg.Start(game)
+Run catango <command> -h for a command's flags.
+`
 
-	playerSelector := &gameplay.CLIPlayerSelector{}
-	startingPlayer := playerSelector.SelectStartingPlayer(game, cg.Input)
+var commands = map[string]func(args []string) error{
+	"play":     runPlay,
+	"load":     runLoad,
+	"simulate": runSimulate,
+	"analyze":  runAnalyze,
+	"serve":    runServe,
+}
 
-	fmt.Printf("Starting player is: Player %d\n", startingPlayer.ID)
-	cg.SnakeBuild(game, startingPlayer, playerCount)
+func main() {
+	args := os.Args[1:]
+	command := "play"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	if command == "help" {
+		fmt.Print(usage)
+		return
+	}
+	run, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err := run(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"catango/gameplay"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes a script for --input into the test's temporary directory
+func writeScript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParsePlay(t *testing.T) {
+	script := writeScript(t, "seed 9", "enter")
+	tests := []struct {
+		args     []string
+		expected string // Part of the error, or empty if the flags are fine
+	}{
+		{[]string{"--players", "3", "--bots", "1", "--bot", "hard", "--seed", "42"}, ""},
+		{[]string{"--bot", "mcts:50", "--input", script}, ""},
+		{[]string{"--players", "1"}, "--players must be 2 to 6"},
+		{[]string{"--players", "7"}, "--players must be 2 to 6"},
+		{[]string{"--players", "three"}, "invalid value"},
+		{[]string{"--players", "2", "--bots", "3"}, "--bots must be"},
+		{[]string{"--bots", "-1"}, "--bots must be"},
+		{[]string{"--bot", "clever"}, "--bot: unknown agent"},
+		{[]string{"--bot", "mcts:0"}, "--bot: invalid MCTS iterations"},
+		{[]string{"--seed", "soon"}, "invalid value"},
+		{[]string{"--input", filepath.Join(t.TempDir(), "missing.txt")}, "no such file"},
+		{[]string{"extra"}, "unexpected"},
+	}
+	for _, test := range tests {
+		_, _, err := parsePlay(test.args, strings.NewReader(""))
+		if test.expected == "" && err != nil || test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+			t.Errorf("%q: got %v, expected %q", test.args, err, test.expected)
+		}
+	}
+
+	cg, game, err := parsePlay([]string{"--players", "4", "--bots", "2", "--seed", "42", "--input", script}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *game.players != 4 || *game.bots != 2 || *game.seed != 42 {
+		t.Errorf("got %d players, %d bots and seed %d", *game.players, *game.bots, *game.seed)
+	}
+	if input, ok := cg.Input.(*gameplay.Script); !ok || input.Seed != 9 {
+		t.Errorf("the input is %T, expected the script with seed 9", cg.Input)
+	}
+}
+
+// A seeded game between bots plays to the same end every time
+func TestPlayBots(t *testing.T) {
+	args := []string{"--players", "2", "--bots", "2", "--bot", "medium", "--seed", "3"}
+	var first, second strings.Builder
+	if err := playAt(append(args, "--input", writeScript(t, "expect phase = finished")), nil, &first); err != nil {
+		t.Fatalf("%v\n%s", err, first.String())
+	}
+	if err := playAt(append(args, "--input", writeScript(t, "expect winner = 0")), nil, &second); err == nil {
+		t.Error("the game was not won")
+	}
+	if first.String() != second.String() {
+		t.Error("the same seed played a different game")
+	}
+}
+
+// A game stopped when its input runs out carries on from where it was saved
+func TestPlayThenLoad(t *testing.T) {
+	save := filepath.Join(t.TempDir(), "game.json")
+	var output strings.Builder
+	args := []string{"--players", "2", "--seed", "5", "--save", save, "--input", writeScript(t, "enter", "enter")}
+	if err := playAt(args, nil, &output); err != nil {
+		t.Fatalf("%v\n%s", err, output.String())
+	}
+	if !strings.Contains(output.String(), "saved in "+save) {
+		t.Fatalf("the game was not saved:\n%s", output.String())
+	}
+
+	output.Reset()
+	if err := loadAt([]string{save, "--input", writeScript(t, "expect phase = setup")}, nil, &output); err != nil {
+		t.Errorf("%v\n%s", err, output.String())
+	}
+	if err := loadAt(nil, nil, &output); err == nil || !strings.Contains(err.Error(), "which game") {
+		t.Errorf("loading no game gave %v", err)
+	}
+	if err := loadAt([]string{save, save}, nil, &output); err == nil || !strings.Contains(err.Error(), "only one game") {
+		t.Errorf("loading two games gave %v", err)
+	}
+}
+
+func TestServeFlags(t *testing.T) {
+	for _, args := range [][]string{{"--players", "9"}, {"--bot", "clever"}, {"--addr"}} {
+		if err := runServe(args); err == nil {
+			t.Errorf("%q was accepted", args)
+		}
+	}
+}
+
+func TestAnalyzeFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--layout", "swirl"},
+		{"--board", "moon"},
+		{"--players", "-1"},
+		{"--players", "7"},
+		{"--players", "4", "--board", "extension"},
+		{"--openings", "-1"},
+		{"--vertices", "-2"},
+	} {
+		if err := runAnalyze(args); err == nil {
+			t.Errorf("%q was accepted", args)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"catango/gameplay"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Flags shared by the commands that play at a terminal
+type sessionFlags struct {
+	fullScreen *bool
+	openHands  *bool
+	input      *string
+	save       *string
+}
+
+func addSessionFlags(fs *flag.FlagSet) *sessionFlags {
+	return &sessionFlags{
+		fullScreen: fs.Bool("fullscreen", false, "draw the game full screen, with the board, hands and log always in view"),
+		openHands:  fs.Bool("open-hands", false, "show everyone's cards instead of passing the terminal between turns, for teaching games"),
+		input:      fs.String("input", "", "play the script in this file instead of reading the terminal, see gameplay/script.go"),
+		save:       fs.String("save", "", "where to save the game if it is stopped (default "+gameplay.DefaultSavePath+")"),
+	}
+}
+
+// The CLI game the flags ask for
+func (sf *sessionFlags) cliGame(input io.Reader) (*gameplay.CLIGame, error) {
+	if *sf.input != "" {
+		script, err := gameplay.LoadScript(*sf.input)
+		if err != nil {
+			return nil, err
+		}
+		input = script
+	}
+	cg := &gameplay.CLIGame{
+		Input:      input,
+		SavePath:   *sf.save,
+		FullScreen: *sf.fullScreen,
+		HotSeat:    !*sf.openHands,
+	}
+	return cg, nil
+}
+
+// Flags choosing who plays a new game
+type gameFlags struct {
+	players *int
+	bots    *int
+	bot     *string
+	seats   *string
+	seed    *int64
+}
+
+func addGameFlags(fs *flag.FlagSet) *gameFlags {
+	return &gameFlags{
+		players: fs.Int("players", 0, "number of players, 2 to 6 (default asked, with everyone's name and colour)"),
+		bots:    fs.Int("bots", 0, "how many of the players are bots, taking the last seats"),
+		bot:     fs.String("bot", "medium", "how the bots play: easy, medium, hard, or random, greedy, mcts[:iterations]"),
+		seats:   fs.String("seats", "", "JSON file naming the players, their colours and which are bots, instead of asking"),
+		seed:    fs.Int64("seed", 0, "seed for the board, dice and bots, to play the same game again (default the script's, or random)"),
+	}
+}
+
+// Checks the flags before any game is started
+func (gf *gameFlags) validate() error {
+	fewest, most := gameplay.BaseBoard.MinPlayers, gameplay.ExtensionBoard.MaxPlayers
+	if *gf.players != 0 && (*gf.players < fewest || *gf.players > most) {
+		return fmt.Errorf("--players must be %d to %d, not %d", fewest, most, *gf.players)
+	}
+	if *gf.bots < 0 || *gf.players != 0 && *gf.bots > *gf.players {
+		return fmt.Errorf("--bots must be 0 to the number of players, not %d", *gf.bots)
+	}
+	if err := gameplay.ValidateBot(*gf.bot); err != nil {
+		return fmt.Errorf("--bot: %w", err)
+	}
+	return nil
+}
+
+// The seats for a new game. Only when no flag says who is playing are the
+// players asked.
+func (gf *gameFlags) chooseSeats(cg *gameplay.CLIGame) ([]gameplay.Seat, error) {
+	if *gf.seats != "" {
+		seats, err := gameplay.LoadSeats(*gf.seats)
+		if err == nil && *gf.players != 0 && *gf.players != len(seats) {
+			err = fmt.Errorf("%s has %d seats, not %d players", *gf.seats, len(seats), *gf.players)
+		}
+		return seats, err
+	}
+	count := *gf.players
+	if count == 0 {
+		var err error
+		if count, err = cg.Initialize(); err != nil {
+			return nil, err
+		}
+		if *gf.bots == 0 {
+			return cg.AskSeats(count)
+		}
+	}
+	if *gf.bots < 0 || *gf.bots > count {
+		return nil, fmt.Errorf("%d bots cannot sit at %d seats", *gf.bots, count)
+	}
+	seats := make([]gameplay.Seat, count)
+	for i := count - *gf.bots; i < count; i++ {
+		seats[i] = gameplay.Seat{Type: gameplay.SeatBot, Bot: *gf.bot}
+	}
+	return seats, gameplay.ValidateSeats(seats)
+}
+
+// Sets up a new game and plays it through, saving it if the input runs out
+func (gf *gameFlags) play(cg *gameplay.CLIGame) error {
+	seed := *gf.seed
+	if script, ok := cg.Input.(*gameplay.Script); ok && seed == 0 {
+		seed = script.Seed
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var game *gameplay.CatanGame
+	var startingPlayer *gameplay.Player
+	seats, err := gf.chooseSeats(cg)
+	if err == nil {
+		game, startingPlayer, err = cg.NewGame(seats, seed)
+	}
+	if errors.Is(err, gameplay.ErrInputClosed) {
+		fmt.Fprintln(cg.Output, "No game started.")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintf(cg.Output, "Starting player is: %s\n", startingPlayer)
+	err = cg.SnakeBuild(game, startingPlayer)
+	if err == nil {
+		err = cg.Play(game)
+	}
+	return err
+}
+
+// catango play: a new game at this terminal
+func runPlay(args []string) error {
+	return playAt(args, os.Stdin, os.Stdout)
+}
+
+// Plays a new game reading input and writing output, the terminal's but
+// for tests
+func playAt(args []string, input io.Reader, output io.Writer) error {
+	cg, game, err := parsePlay(args, input)
+	if err != nil {
+		return err
+	}
+	cg.Output = output
+	defer cg.Close()
+	return finished(cg, game.play(cg))
+}
+
+// The CLI game play's flags ask for, and who is to play in it
+func parsePlay(args []string, input io.Reader) (*gameplay.CLIGame, *gameFlags, error) {
+	fs := flag.NewFlagSet("play", flag.ContinueOnError)
+	session := addSessionFlags(fs)
+	game := addGameFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, nil, fmt.Errorf("unexpected %q, play only takes flags", fs.Arg(0))
+	}
+	if err := game.validate(); err != nil {
+		return nil, nil, err
+	}
+	cg, err := session.cliGame(input)
+	return cg, game, err
+}
+
+// Input running out is how a game is stopped, the game having been saved.
+// A script's expectations are checked to its end.
+func finished(cg *gameplay.CLIGame, err error) error {
+	if errors.Is(err, gameplay.ErrInputClosed) {
+		err = nil
+	}
+	if script, ok := cg.Input.(*gameplay.Script); ok && err == nil {
+		err = script.Finish()
+	}
+	if err != nil {
+		cg.Close() // So the error shows on the usual screen
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"catango/gameplay"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// catango serve: a new game for every TCP connection, played by whoever is
+// connected against the bots, as at a terminal. A game with remote seats
+// waits for them to be taken by the next connections to arrive.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:7878", "address to listen on")
+	openHands := fs.Bool("open-hands", false, "show everyone's cards instead of passing the terminal between turns")
+	saveDir := fs.String("save-dir", ".", "directory where games stopped by a dropped connection are saved")
+	game := addGameFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := game.validate(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	log.Printf("serving games on %s, connect with: nc %s %s", listener.Addr(), host, port)
+	var count atomic.Int64
+	joining := make(chan net.Conn)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		select {
+		case joining <- conn:
+			continue // Taken by a game waiting for a remote seat
+		default:
+		}
+		n := count.Add(1)
+		go func() {
+			var remotes []net.Conn
+			defer func() {
+				conn.Close()
+				for _, remote := range remotes {
+					remote.Close()
+				}
+			}()
+			log.Printf("game %d: %s connected", n, conn.RemoteAddr())
+			cg := &gameplay.CLIGame{
+				Input:    conn,
+				Output:   conn,
+				SavePath: filepath.Join(*saveDir, fmt.Sprintf("catango-game-%d.json", n)),
+				HotSeat:  !*openHands,
+				Join: func(number int, seat gameplay.Seat) (io.ReadWriter, error) {
+					fmt.Fprintf(conn, "Waiting for someone to connect to %s for seat %d...\n", listener.Addr(), number)
+					remote := <-joining
+					remotes = append(remotes, remote)
+					log.Printf("game %d: %s joined for seat %d", n, remote.RemoteAddr(), number)
+					return remote, nil
+				},
+			}
+			if err := finished(cg, game.play(cg)); err != nil {
+				fmt.Fprintln(conn, err)
+				log.Printf("game %d: %v", n, err)
+			}
+			log.Printf("game %d: %s left", n, conn.RemoteAddr())
+		}()
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"catango/gameplay"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// catango simulate: play bot games headless and report the results
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	games := fs.Int("games", 100, "number of games to play")
+	agents := fs.String("agents", "greedy,greedy,greedy,random", "comma separated agent per seat: random, greedy, mcts[:iterations]")
+	seed := fs.Int64("seed", 1, "seed of the first game, game i uses seed+i")
+	workers := fs.Int("workers", runtime.NumCPU(), "games played in parallel")
+	maxTurns := fs.Int("max-turns", 500, "turns before a game is abandoned without a winner")
+	csvPath := fs.String("csv", "", "also write one CSV row per game to this file")
+	board := fs.String("board", "", "board to play on: base, beginner, extension or seafarers (default picked by player count)")
+	layout := fs.String("layout", "", "how tiles and numbers are laid out: random, spiral or fixed (default the board's own)")
+	boardFile := fs.String("board-file", "", "play on the board described in this JSON file")
+	fair := fs.Bool("fair", false, "only play on balanced boards: no touching red or equal numbers, no big resource clusters")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *games < 1 {
+		return fmt.Errorf("--games must be at least 1, not %d", *games)
+	}
+
+	config := gameplay.SimulationConfig{
+		Games:    *games,
+		Agents:   strings.Split(*agents, ","),
+		Seed:     *seed,
+		Workers:  *workers,
+		MaxTurns: *maxTurns,
+		Board:    *board,
+		Layout:   gameplay.BoardLayout(*layout),
+	}
+	if *fair {
+		config.Constraints = gameplay.FairBoard
+	}
+	if *boardFile != "" {
+		file, err := gameplay.LoadBoardFile(*boardFile)
+		if err != nil {
+			return err
+		}
+		config.BoardFile = file
+	}
+	report, err := gameplay.RunSimulations(config)
+	if err != nil {
+		return err
+	}
+	report.WriteTable(os.Stdout)
+
+	if *csvPath != "" {
+		file, err := os.Create(*csvPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := report.WriteCSV(file); err != nil {
+			return fmt.Errorf("writing %s: %w", *csvPath, err)
+		}
+	}
+	return nil
+}
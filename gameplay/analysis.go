@@ -0,0 +1,175 @@
+// analysis.go
+
+// Board statistics for planning openings and checking generated boards: what
+// each vertex produces, which ports it reaches, and which pairs of starting
+// settlements look strongest.
+package gameplay
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// VertexStats is what a settlement on one vertex would get
+type VertexStats struct {
+	VertexID int
+	Pips     int                // Ways of rolling its numbers out of 36
+	Income   map[string]float64 // Expected cards of each resource per roll
+	Port     string             // "A" for 3:1, a resource for 2:1, "" for none
+}
+
+// Expected cards per roll over all resources
+func (v VertexStats) TotalIncome() float64 {
+	total := 0.0
+	for _, amount := range v.Income {
+		total += amount
+	}
+	return total
+}
+
+// Opening is a pair of starting settlements
+type Opening struct {
+	VertexIDs [2]int
+	Pips      int
+	Resources int // Different resources produced
+	Port      string
+	Score     float64
+}
+
+type BoardAnalysis struct {
+	Vertices       []VertexStats      // By vertex ID, from 1
+	ResourcePips   map[string]int     // Pips of every tile of each resource
+	ResourceIncome map[string]float64 // Expected cards per roll from a settlement next to every tile
+	Balance        float64            // BalanceScore of the board
+	Openings       []Opening          // Best first
+}
+
+// AnalyzeBoard works out the statistics for a board with nothing built on it,
+// ignoring the robber. Only the top openings are kept, none if openings is
+// negative.
+func AnalyzeBoard(board *Board, openings int) *BoardAnalysis {
+	openings = max(openings, 0)
+	graph := board.Graph
+	analysis := &BoardAnalysis{
+		ResourcePips:   make(map[string]int),
+		ResourceIncome: make(map[string]float64),
+		Balance:        BalanceScore(board),
+	}
+	for _, tile := range board.Tiles {
+		if tile.Produces() {
+			analysis.ResourcePips[tile.Resource] += pips(tile.NumberToken)
+			analysis.ResourceIncome[tile.Resource] += float64(pips(tile.NumberToken)) / 36
+		}
+	}
+
+	ports := make(map[int]string)
+	for _, port := range board.Ports {
+		ports[port.VertexIDs[0]] = port.GiveResource
+		ports[port.VertexIDs[1]] = port.GiveResource
+	}
+	for id := 1; id <= graph.VertexCount(); id++ {
+		stats := VertexStats{VertexID: id, Income: make(map[string]float64), Port: ports[id]}
+		for _, tileID := range graph.layout.vertices[id].TileIds {
+			tile := board.Tiles[tileID-1]
+			if !tile.Produces() {
+				continue
+			}
+			stats.Pips += pips(tile.NumberToken)
+			stats.Income[tile.Resource] += float64(pips(tile.NumberToken)) / 36
+		}
+		analysis.Vertices = append(analysis.Vertices, stats)
+	}
+
+	for a := 1; a <= graph.VertexCount(); a++ {
+		if VertexIsland(board, a) != 0 {
+			continue // Setup settlements go on the home island
+		}
+		for b := a + 1; b <= graph.VertexCount(); b++ {
+			if containsVertex(graph.Neighbors(a), b) || VertexIsland(board, b) != 0 {
+				continue // Distance rule
+			}
+			analysis.Openings = append(analysis.Openings, scoreOpening(analysis.Vertices[a-1], analysis.Vertices[b-1]))
+		}
+	}
+	sort.SliceStable(analysis.Openings, func(i, j int) bool {
+		return analysis.Openings[i].Score > analysis.Openings[j].Score
+	})
+	if len(analysis.Openings) > openings {
+		analysis.Openings = analysis.Openings[:openings]
+	}
+	return analysis
+}
+
+// An opening scores its pips, plus 2 for each different resource since a
+// spread of resources is needed to build anything, plus 1 for a 3:1 port or 3
+// for a 2:1 port in a resource the pair produces
+func scoreOpening(a, b VertexStats) Opening {
+	opening := Opening{VertexIDs: [2]int{a.VertexID, b.VertexID}, Pips: a.Pips + b.Pips}
+	income := make(map[string]float64)
+	for _, stats := range []VertexStats{a, b} {
+		for resource, amount := range stats.Income {
+			income[resource] += amount
+		}
+		if stats.Port != "" && (opening.Port == "" || opening.Port == "A") {
+			opening.Port = stats.Port
+		}
+	}
+	opening.Resources = len(income)
+	opening.Score = float64(opening.Pips) + 2*float64(opening.Resources)
+	switch {
+	case opening.Port == "A":
+		opening.Score++
+	case opening.Port != "" && income[opening.Port] > 0:
+		opening.Score += 3
+	}
+	return opening
+}
+
+// WriteReport prints the analysis for people to read. Vertices are listed
+// best first, stopping after vertices of them.
+func (a *BoardAnalysis) WriteReport(w io.Writer, vertices int) {
+	vertices = max(vertices, 0)
+	fmt.Fprintf(w, "Balance score: %.2f\n\n", a.Balance)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Resource\tPips\tCards per roll\t")
+	for _, resource := range ResourceTypes {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t\n", resource, a.ResourcePips[resource], a.ResourceIncome[resource])
+	}
+	tw.Flush()
+
+	ranked := append([]VertexStats(nil), a.Vertices...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Pips > ranked[j].Pips })
+	if len(ranked) > vertices {
+		ranked = ranked[:vertices]
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Vertex\tPips\tCards per roll\tProduces\tPort\t")
+	for _, v := range ranked {
+		fmt.Fprintf(tw, "%d\t%d\t%.2f\t%s\t%s\t\n", v.VertexID, v.Pips, v.TotalIncome(), describeIncome(v.Income), v.Port)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Rank\tSettlements\tPips\tResources\tPort\tScore\t")
+	for i, o := range a.Openings {
+		fmt.Fprintf(tw, "%d\t%d + %d\t%d\t%d\t%s\t%.1f\t\n", i+1, o.VertexIDs[0], o.VertexIDs[1], o.Pips, o.Resources, o.Port, o.Score)
+	}
+	tw.Flush()
+}
+
+// Resources in the usual order with their share of the income, like "W.14 O.08"
+func describeIncome(income map[string]float64) string {
+	var parts []string
+	for _, resource := range ResourceTypes {
+		if income[resource] > 0 {
+			parts = append(parts, fmt.Sprintf("%s%s", resource, strings.TrimPrefix(fmt.Sprintf("%.2f", income[resource]), "0")))
+		}
+	}
+	return strings.Join(parts, " ")
+}
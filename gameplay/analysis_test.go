@@ -0,0 +1,49 @@
+package gameplay
+
+import (
+	"io"
+	"testing"
+)
+
+func TestAnalyzeBoard(t *testing.T) {
+	game, _ := NewCatanGameOnBoard([]int{1, 2, 3}, BeginnerBoard, 1)
+	analysis := AnalyzeBoard(game.Board, 20)
+
+	for _, stats := range analysis.Vertices {
+		if stats.Pips != VertexPips(game, stats.VertexID) {
+			t.Errorf("vertex %d has %d pips, expected %d", stats.VertexID, stats.Pips, VertexPips(game, stats.VertexID))
+		}
+		if diff := stats.TotalIncome() - float64(stats.Pips)/36; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("vertex %d income %.3f does not match its pips", stats.VertexID, stats.TotalIncome())
+		}
+	}
+	// Every number but 7 appears on the board, 58 pips in all
+	total := 0
+	for _, resource := range ResourceTypes {
+		total += analysis.ResourcePips[resource]
+	}
+	if total != 58 {
+		t.Errorf("board has %d pips, expected 58", total)
+	}
+
+	if len(analysis.Openings) != 20 {
+		t.Fatalf("got %d openings, expected 20", len(analysis.Openings))
+	}
+	for i, opening := range analysis.Openings {
+		if containsVertex(game.Board.Graph.Neighbors(opening.VertexIDs[0]), opening.VertexIDs[1]) {
+			t.Errorf("opening %v breaks the distance rule", opening.VertexIDs)
+		}
+		if i > 0 && opening.Score > analysis.Openings[i-1].Score {
+			t.Errorf("opening %d scores more than the one before it", i+1)
+		}
+	}
+}
+
+func TestAnalyzeNegativeCounts(t *testing.T) {
+	game, _ := NewCatanGameOnBoard([]int{1, 2, 3}, BeginnerBoard, 1)
+	analysis := AnalyzeBoard(game.Board, -1)
+	if len(analysis.Openings) != 0 {
+		t.Errorf("got %d openings, expected none", len(analysis.Openings))
+	}
+	analysis.WriteReport(io.Discard, -2)
+}
@@ -2,6 +2,7 @@
 package gameplay
 
 import (
+	"catango/helpers"
 	"fmt"
 )
 
@@ -57,60 +58,46 @@ func (bps *BasePlayerSelector) SelectStartingPlayer(game *CatanGame, roll DiceRo
 	return contenders[0]
 }
 
-func GenerateSnakeOrder(game *CatanGame, startingPlayer *Player, totalPlayers int) []int {
-	var order []int //Build a stack to create the snake
-
-	startIdx := -1
+// GenerateSnakeOrder lists player IDs in setup placement order: round the
+// table from the starting player, then back again in reverse, so the last
+// player places twice in a row
+func GenerateSnakeOrder(game *CatanGame, startingPlayer *Player) []int {
+	startIdx := 0 // Falls back to the first player if startingPlayer is not playing
 	for i, player := range game.Players {
 		if player.ID == startingPlayer.ID {
 			startIdx = i
 			break
 		}
 	}
-	if startIdx == -1 {
-		// fallback: default to 0 if not found
-		startIdx = 0
-	} // Find the index of the starting player
 
-	// Wraparound loop to create the first placement order
-	for i := startIdx; i < totalPlayers; i++ {
-		order = append(order, game.Players[i].ID)
-	}
-	for i := 0; i < startIdx; i++ {
-		order = append(order, game.Players[i].ID)
+	n := len(game.Players)
+	order := make([]int, 0, 2*n)
+	for i := 0; i < n; i++ {
+		order = append(order, game.Players[(startIdx+i)%n].ID)
 	}
-
-	for i := totalPlayers - 1; i >= 0; i-- {
+	for i := n - 1; i >= 0; i-- {
 		order = append(order, order[i])
 	}
-	fmt.Printf("%v", order)
-
 	return order
 }
 
 // Get the list of valid edges for a given vertex regardless of who owns it
 // Check one VertexID and then see what edges (max of 3) are available
+// Each edge is returned as the ID of the vertex at its other end
 func ComputeValidEdgePlacements(game *CatanGame, vertexID int) []int {
 	var EdgeIDs []int
-	//vertex := game.Board.Graph.Vertices[strconv.Itoa(vertexID)]
+	for _, adjID := range GetAdjacentVertices(vertexID, game) {
+		if RoadEmptySpace(vertexID, adjID, game) {
+			EdgeIDs = append(EdgeIDs, adjID)
+		}
+	}
 
 	return EdgeIDs
 }
 
 func GetAdjacentVertices(vertexID int, game *CatanGame) []int {
-	var adjacentVertexIDs []int
-	vertex, exists := game.Board.Graph.Vertices[vertexID]
-	if !exists {
-		return adjacentVertexIDs // Return empty if vertex does not exist
-	}
-
-	for _, adjID := range vertex.AdjacentVertexes {
-		if adjVertex, exists := game.Board.Graph.Vertices[adjID]; exists {
-			adjacentVertexIDs = append(adjacentVertexIDs, adjVertex.ID)
-		}
-	}
-
-	return adjacentVertexIDs
+	// Empty if vertex does not exist
+	return append([]int(nil), game.Board.Graph.Neighbors(vertexID)...)
 }
 
 // get the list of valid vertex placements for a player at the beginning of a game
@@ -119,26 +106,23 @@ func GetAdjacentVertices(vertexID int, game *CatanGame) []int {
 // Also needs to verify that the vertex is at least two spaces away from another player's settlement
 func ComputeValidVertexPlacements(game *CatanGame) []int {
 	var VertexIDs []int
+	graph := game.Board.Graph
 
-	for _, vertex := range game.Board.Graph.Vertices {
-		if vertex.OccupiedBy != nil || vertex.Building != 0 {
-			continue // Skip if already occupied or has a building
+	for vertexID := 1; vertexID <= graph.VertexCount(); vertexID++ {
+		if graph.vertexOwners[vertexID] != 0 || VertexIsland(game.Board, vertexID) < 0 {
+			continue // Skip if already occupied or out at sea
 		}
 
 		valid := true
-		for _, adjID := range vertex.AdjacentVertexes {
-			adjVertex, exists := game.Board.Graph.Vertices[adjID]
-			if !exists {
-				continue // skip if adjacent vertex doesn't exist (defensive check)
-			}
-			if adjVertex.OccupiedBy != nil {
+		for _, adjID := range graph.Neighbors(vertexID) {
+			if graph.vertexOwners[adjID] != 0 {
 				valid = false
 				break
 			}
 		}
 
 		if valid {
-			VertexIDs = append(VertexIDs, vertex.ID)
+			VertexIDs = append(VertexIDs, vertexID)
 		}
 	}
 
@@ -147,47 +131,205 @@ func ComputeValidVertexPlacements(game *CatanGame) []int {
 
 // Checks if vertex is empty, player can afford it, and if it is adjacent to a road
 func ValidateAndPlaceSettlement(vertexID int, player *Player, game *CatanGame) bool {
+	if !helpers.ContainsInt(ComputeValidSettlementPlacements(game, player), vertexID) || !CanPlayerAfford(player, "settlement") {
+		return false
+	}
+	payCost(game, player, "settlement")
+	PlaceSettlement(vertexID, player, game)
+	return true
+}
 
-	return false
+// Valid settlement spots for a player after setup: the distance rule from
+// ComputeValidVertexPlacements plus a connection to one of the player's roads
+// or ships
+func ComputeValidSettlementPlacements(game *CatanGame, player *Player) []int {
+	var VertexIDs []int
+	if countBuildings(game, player, 1) >= MaxSettlements {
+		return VertexIDs
+	}
+
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for _, vertexID := range ComputeValidVertexPlacements(game) {
+		for _, edge := range graph.layout.vertexEdges[vertexID] {
+			if graph.edgeOwners[edge] == slot || graph.shipOwners[edge] == slot {
+				VertexIDs = append(VertexIDs, vertexID)
+				break
+			}
+		}
+	}
+
+	return VertexIDs
+}
+
+// Settlements the player could upgrade to a city
+func ComputeValidCityPlacements(game *CatanGame, player *Player) []int {
+	var VertexIDs []int
+	if countBuildings(game, player, 2) >= MaxCities {
+		return VertexIDs
+	}
+
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for vertexID := 1; vertexID <= graph.VertexCount(); vertexID++ {
+		if graph.vertexOwners[vertexID] == slot && graph.vertexBuildings[vertexID] == 1 {
+			VertexIDs = append(VertexIDs, vertexID)
+		}
+	}
+
+	return VertexIDs
+}
+
+// Assume validation has already been done
+func PlaceCity(vertexID int, player *Player, game *CatanGame) {
+	if VertexOwner(game, vertexID) == player && VertexBuilding(game, vertexID) == 1 {
+		setBuilding(game, vertexID, player, 2)
+		player.VictoryPoints += 1 // A city is worth one more point than the settlement it replaces
+	}
 }
 
 // Assume validation has already been done
 func PlaceSettlement(vertexID int, player *Player, game *CatanGame) {
-	vertex := game.Board.Graph.Vertices[vertexID]
-	if vertex.OccupiedBy == nil {
-		vertex.OccupiedBy = player
-		vertex.Building = 1       // 1 for settlement
-		player.VictoryPoints += 1 // Increment player's victory points
+	if VertexOwner(game, vertexID) == nil {
+		setBuilding(game, vertexID, player, 1) // 1 for settlement
+		player.VictoryPoints += 1              // Increment player's victory points
+		settleIsland(game, player, vertexID)
 	}
 }
 
-// Checks if the place the player wants to build a road is empty
-func RoadEmptySpace() {
+// EdgeKey is the ID of the edge between two vertices, as in Edge.ID,
+// always written lowest vertex ID first
+func EdgeKey(vertexID1, vertexID2 int) string {
+	return fmt.Sprintf("%d-%d", min(vertexID1, vertexID2), max(vertexID1, vertexID2))
+}
 
+// Checks if the place the player wants to build a road or ship is empty
+func RoadEmptySpace(vertexID1, vertexID2 int, game *CatanGame) bool {
+	edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2)
+	if edge < 0 {
+		return false // Not an edge of the board
+	}
+	return game.Board.Graph.edgeOwners[edge] == 0 && game.Board.Graph.shipOwners[edge] == 0
 }
 
-// Validates that the player can place a road,
-func ValidateAndPlaceRoad() {
+// A road is connected if one of its ends has the player's building, or has
+// another of the player's roads that is not cut off by an opponent's building
+func roadConnects(vertexID1, vertexID2 int, player *Player, game *CatanGame) bool {
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for _, end := range [2]int{vertexID1, vertexID2} {
+		if graph.vertexOwners[end] == slot {
+			return true
+		}
+		if graph.vertexOwners[end] != 0 {
+			continue
+		}
+		for _, edge := range graph.layout.vertexEdges[end] {
+			if graph.edgeOwners[edge] == slot {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Every edge the player could build a road on, ignoring cost
+func ComputeValidRoadPlacements(game *CatanGame, player *Player) [][2]int {
+	var roads [][2]int
+	if countRoads(game, player) >= MaxRoads {
+		return roads
+	}
 
+	graph := game.Board.Graph
+	for edge := 0; edge < graph.EdgeCount(); edge++ {
+		a, b := graph.EdgeVertices(edge)
+		if landEdge(game.Board, edge) && RoadEmptySpace(a, b, game) && roadConnects(a, b, player, game) {
+			roads = append(roads, [2]int{a, b})
+		}
+	}
+
+	return roads
+}
+
+// Validates that the player can place a road,
+func ValidateAndPlaceRoad(vertexID1, vertexID2 int, player *Player, game *CatanGame) bool {
+	if countRoads(game, player) >= MaxRoads || !RoadEmptySpace(vertexID1, vertexID2, game) ||
+		!landEdge(game.Board, game.Board.Graph.EdgeBetween(vertexID1, vertexID2)) || !roadConnects(vertexID1, vertexID2, player, game) {
+		return false
+	}
+	if game.FreeRoads > 0 {
+		game.FreeRoads--
+	} else if CanPlayerAfford(player, "road") {
+		payCost(game, player, "road")
+	} else {
+		return false
+	}
+	PlaceRoad(vertexID1, vertexID2, player, game)
+	return true
 }
 
 // Assumes they can afford it, used solo when road building card or snake build is used
 func PlaceRoad(vertexID1, vertexID2 int, player *Player, game *CatanGame) {
-	edgeKey := fmt.Sprintf("%d-%d", vertexID1, vertexID2)
-
-	var Road = Edge{
-		ID:         edgeKey,
-		OccupiedBy: player,
-		Vertices:   [2]*Vertex{game.Board.Graph.Vertices[vertexID1], game.Board.Graph.Vertices[vertexID2]},
+	if edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2); edge >= 0 {
+		setRoad(game, edge, player)
 	}
+}
+
+// Piece limits per player
+const (
+	MaxRoads       = 15
+	MaxSettlements = 5
+	MaxCities      = 4
+)
 
-	game.Board.Graph.Edges[edgeKey] = &Road
+// Resource cost of everything a player can buy
+var BuildCosts = map[string]map[string]int{
+	"road":       {"B": 1, "L": 1},
+	"ship":       {"L": 1, "S": 1},
+	"settlement": {"B": 1, "L": 1, "S": 1, "W": 1},
+	"city":       {"W": 2, "O": 3},
+	"dev":        {"S": 1, "W": 1, "O": 1},
 }
 
 // Pass in the player and what the player wants to buy
 // Returns true if the player can afford the resource, false otherwise
-func CanPlayerAfford() {
+func CanPlayerAfford(player *Player, item string) bool {
+	for resource, amount := range BuildCosts[item] {
+		if player.Resources[resource] < amount {
+			return false
+		}
+	}
+	return true
+}
 
+// Moves the cost of an item from the player to the bank, assumes they can afford it
+func payCost(game *CatanGame, player *Player, item string) {
+	for resource, amount := range BuildCosts[item] {
+		PlayerToBankResource(game, player, resource, amount)
+	}
+}
+
+func countBuildings(game *CatanGame, player *Player, building int) int {
+	count := 0
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for vertexID, owner := range graph.vertexOwners {
+		if owner == slot && slot != 0 && int(graph.vertexBuildings[vertexID]) == building {
+			count++
+		}
+	}
+	return count
+}
+
+func countRoads(game *CatanGame, player *Player) int {
+	count := 0
+	slot := playerSlot(game, player)
+	for _, owner := range game.Board.Graph.edgeOwners {
+		if owner == slot && slot != 0 {
+			count++
+		}
+	}
+	return count
 }
 
 func BankToPlayerResource(game *CatanGame, player *Player, resource string, amount int) bool {
@@ -199,23 +341,32 @@ func BankToPlayerResource(game *CatanGame, player *Player, resource string, amou
 	return false // Not enough resources in the bank
 }
 
-func PlayerToBankResource() {
-
+func PlayerToBankResource(game *CatanGame, player *Player, resource string, amount int) bool {
+	if player.Resources[resource] >= amount {
+		player.Resources[resource] -= amount
+		game.Bank.Resources[resource] += amount
+		return true
+	}
+	return false // Player does not have enough of the resource
 }
 
-func GetTileByID(game *CatanGame, tileID int) *Tile {
-	for _, tile := range game.Board.Tiles {
-		if tile.ID == tileID {
-			return tile
-		}
+func GetTileByID(game *CatanGame, tileID TileID) *Tile {
+	if tileID < 1 || int(tileID) > len(game.Board.Tiles) {
+		return nil // Return nil if no tile found with the given ID
 	}
-	return nil // Return nil if no tile found with the given ID
+	return game.Board.Tiles[tileID-1]
 }
 
+// Returns a snapshot of the vertex, changing it does not change the board
 func GetVertexByID(game *CatanGame, vertexID int) *Vertex {
-	vertex, exists := game.Board.Graph.Vertices[vertexID]
-	if !exists {
+	graph := game.Board.Graph
+	if vertexID < 1 || vertexID > graph.VertexCount() {
 		return nil // Return nil if vertex does not exist
 	}
-	return vertex
+	vertex := graph.layout.vertices[vertexID]
+	vertex.AdjacentVertexes = append([]int(nil), vertex.AdjacentVertexes...)
+	vertex.TileIds = append([]TileID(nil), vertex.TileIds...)
+	vertex.OccupiedBy = VertexOwner(game, vertexID)
+	vertex.Building = VertexBuilding(game, vertexID)
+	return &vertex
 }
@@ -0,0 +1,214 @@
+// boardBalance.go
+
+// Rules a generated board can be asked to follow, and a score for how fair a
+// board is. Boards are generated by shuffling, swapping clashing numbers
+// around, and shuffling again until every rule holds.
+package gameplay
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+type BoardConstraints struct {
+	NoAdjacentRedNumbers  bool    // No 6 next to a 6 or 8, no 8 next to an 8
+	NoAdjacentSameNumbers bool    // No two neighbouring tiles with the same number
+	MaxResourceCluster    int     // Most tiles of one resource allowed to touch as a group, 0 for no limit
+	MinBalance            float64 // Lowest BalanceScore accepted, 0 for any
+}
+
+// FairBoard is what to ask for when the board should not favour anyone
+var FairBoard = BoardConstraints{
+	NoAdjacentRedNumbers:  true,
+	NoAdjacentSameNumbers: true,
+	MaxResourceCluster:    2,
+	MinBalance:            0.75, // A shuffled base board averages about 0.73
+}
+
+// Shuffles tried before giving up on a set of constraints
+const maxBoardAttempts = 2000
+
+// Check returns the first constraint the board breaks, nil if it keeps them all
+func (c BoardConstraints) Check(board *Board) error {
+	neighbours := tileNeighbours(board)
+	for i, tile := range board.Tiles {
+		for _, j := range neighbours[i] {
+			other := board.Tiles[j]
+			if tile.NumberToken == 0 || other.NumberToken == 0 {
+				continue
+			}
+			if c.NoAdjacentRedNumbers && isRed(tile.NumberToken) && isRed(other.NumberToken) {
+				return fmt.Errorf("tiles %d and %d both have red numbers", tile.ID, other.ID)
+			}
+			if c.NoAdjacentSameNumbers && tile.NumberToken == other.NumberToken {
+				return fmt.Errorf("tiles %d and %d both have %d", tile.ID, other.ID, tile.NumberToken)
+			}
+		}
+	}
+	if c.MaxResourceCluster > 0 {
+		if size, resource := largestResourceCluster(board, neighbours); size > c.MaxResourceCluster {
+			return fmt.Errorf("%d %s tiles touch, at most %d may", size, resource, c.MaxResourceCluster)
+		}
+	}
+	if c.MinBalance > 0 {
+		if score := BalanceScore(board); score < c.MinBalance {
+			return fmt.Errorf("balance score %.2f is under %.2f", score, c.MinBalance)
+		}
+	}
+	return nil
+}
+
+// Pairs of neighbouring tiles whose numbers break the constraints
+func (c BoardConstraints) numberClashes(board *Board, neighbours [][]int) int {
+	clashes := 0
+	for i, tile := range board.Tiles {
+		for _, j := range neighbours[i] {
+			a, b := tile.NumberToken, board.Tiles[j].NumberToken
+			if j < i || a == 0 || b == 0 {
+				continue
+			}
+			if (c.NoAdjacentRedNumbers && isRed(a) && isRed(b)) || (c.NoAdjacentSameNumbers && a == b) {
+				clashes++
+			}
+		}
+	}
+	return clashes
+}
+
+// Swaps number tokens between tiles, keeping swaps that leave fewer clashes,
+// since with many duplicate numbers a fresh shuffle rarely has none
+func (c BoardConstraints) repairNumbers(board *Board, rng *rand.Rand) {
+	if !c.NoAdjacentRedNumbers && !c.NoAdjacentSameNumbers {
+		return
+	}
+	neighbours := tileNeighbours(board)
+	var numbered []*Tile
+	for _, tile := range board.Tiles {
+		if tile.NumberToken != 0 {
+			numbered = append(numbered, tile)
+		}
+	}
+
+	clashes := c.numberClashes(board, neighbours)
+	for step := 0; step < 50*len(numbered) && clashes > 0; step++ {
+		a, b := numbered[rng.Intn(len(numbered))], numbered[rng.Intn(len(numbered))]
+		a.NumberToken, b.NumberToken = b.NumberToken, a.NumberToken
+		if after := c.numberClashes(board, neighbours); after <= clashes {
+			clashes = after
+		} else {
+			a.NumberToken, b.NumberToken = b.NumberToken, a.NumberToken
+		}
+	}
+}
+
+func isRed(number int) bool {
+	return number == 6 || number == 8
+}
+
+// Indexes into board.Tiles of each tile's neighbours
+func tileNeighbours(board *Board) [][]int {
+	index := make(map[HexCoord]int, len(board.Tiles))
+	for i, tile := range board.Tiles {
+		index[tile.Coord] = i
+	}
+	neighbours := make([][]int, len(board.Tiles))
+	for i, tile := range board.Tiles {
+		for direction := range hexDirections {
+			if j, ok := index[tile.Coord.Neighbor(direction)]; ok {
+				neighbours[i] = append(neighbours[i], j)
+			}
+		}
+	}
+	return neighbours
+}
+
+// Size and resource of the largest group of touching tiles with the same
+// resource, ignoring the desert and sea
+func largestResourceCluster(board *Board, neighbours [][]int) (int, string) {
+	seen := make([]bool, len(board.Tiles))
+	largest, largestResource := 0, ""
+	for start, tile := range board.Tiles {
+		if seen[start] || !tile.Produces() {
+			continue
+		}
+		size := 0
+		stack := []int{start}
+		seen[start] = true
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for _, j := range neighbours[i] {
+				if !seen[j] && board.Tiles[j].Resource == tile.Resource {
+					seen[j] = true
+					stack = append(stack, j)
+				}
+			}
+		}
+		if size > largest {
+			largest, largestResource = size, tile.Resource
+		}
+	}
+	return largest, largestResource
+}
+
+// BalanceScore rates a board from 0 to 1, with 1 the fairest. It looks at how
+// evenly the dice favour each resource (average pips per tile of each
+// resource) and how evenly the pips are spread over the board (pips around
+// each land tile, counting its land neighbours). Both are measured as a
+// coefficient of variation, so 1 means no variation at all.
+func BalanceScore(board *Board) float64 {
+	resourcePips := make(map[string]int)
+	tiles := make(map[string]int)
+	for _, tile := range board.Tiles {
+		if tile.Produces() {
+			resourcePips[tile.Resource] += pips(tile.NumberToken)
+			tiles[tile.Resource]++
+		}
+	}
+	var perResource []float64
+	for _, resource := range ResourceTypes {
+		if tiles[resource] > 0 {
+			perResource = append(perResource, float64(resourcePips[resource])/float64(tiles[resource]))
+		}
+	}
+
+	neighbours := tileNeighbours(board)
+	var perArea []float64
+	for i, tile := range board.Tiles {
+		if tile.Resource == Sea {
+			continue
+		}
+		total, area := pips(tile.NumberToken), 1
+		for _, j := range neighbours[i] {
+			if board.Tiles[j].Resource != Sea {
+				total += pips(board.Tiles[j].NumberToken)
+				area++
+			}
+		}
+		perArea = append(perArea, float64(total)/float64(area))
+	}
+
+	return 1 / (1 + variation(perResource) + variation(perArea))
+}
+
+// Coefficient of variation: standard deviation over mean
+func variation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+	squares := 0.0
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares/float64(len(values))) / mean
+}
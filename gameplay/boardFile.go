@@ -0,0 +1,348 @@
+// boardFile.go
+
+// Boards described in a JSON file, for testing particular positions and
+// sharing map designs. Tiles are given by axial coordinate (see hex.go), and
+// vertices by the IDs GenerateTopology gives them, which are the numbers
+// players type. For example:
+//
+//	{
+//	  "name": "tiny",
+//	  "tiles": [
+//	    {"q": 0, "r": 0, "resource": "W", "number": 6},
+//	    {"q": 1, "r": 0, "resource": "D"}
+//	  ],
+//	  "robber": {"q": 1, "r": 0},
+//	  "ports": [{"resource": "A", "vertices": [1, 2]}],
+//	  "buildings": [{"player": 1, "vertex": 4, "type": "city"}],
+//	  "roads": [{"player": 1, "vertices": [4, 5]}]
+//	}
+//
+// The robber starts on the first desert if none is given. Seafarers boards
+// (see seafarers.go) add "Sea" tiles, "ships" listed like roads, and an
+// "islandBonus". Problems are reported against the entry they were found in,
+// like "tiles[3]: ...".
+package gameplay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type BoardFile struct {
+	Name        string          `json:"name,omitempty"`
+	Tiles       []TileEntry     `json:"tiles"`
+	Robber      *CoordEntry     `json:"robber,omitempty"`
+	Ports       []PortEntry     `json:"ports,omitempty"`
+	Buildings   []BuildingEntry `json:"buildings,omitempty"`
+	Roads       []RoadEntry     `json:"roads,omitempty"`
+	Ships       []RoadEntry     `json:"ships,omitempty"`
+	IslandBonus int             `json:"islandBonus,omitempty"`
+}
+
+type CoordEntry struct {
+	Q int `json:"q"`
+	R int `json:"r"`
+}
+
+type TileEntry struct {
+	Q        int    `json:"q"`
+	R        int    `json:"r"`
+	Resource string `json:"resource"`
+	Number   int    `json:"number,omitempty"` // Left out for the desert and sea
+}
+
+type PortEntry struct {
+	Resource string `json:"resource"` // "A" for 3:1, otherwise the resource of a 2:1 port
+	Vertices [2]int `json:"vertices"`
+}
+
+type BuildingEntry struct {
+	Player int    `json:"player"`
+	Vertex int    `json:"vertex"`
+	Type   string `json:"type"` // "settlement" or "city"
+}
+
+type RoadEntry struct {
+	Player   int    `json:"player"`
+	Vertices [2]int `json:"vertices"`
+}
+
+var buildingTypes = map[string]int{"settlement": 1, "city": 2}
+
+// LoadBoardFile reads and checks a board file
+func LoadBoardFile(path string) (*BoardFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := ParseBoardFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file, nil
+}
+
+// ParseBoardFile decodes a board file and checks the board it describes
+func ParseBoardFile(data []byte) (*BoardFile, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file BoardFile
+	if err := decoder.Decode(&file); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line, column := position(data, syntax.Offset)
+			return nil, fmt.Errorf("line %d column %d: %v", line, column, err)
+		}
+		return nil, err
+	}
+	if _, err := file.Board(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// Line and column of a byte offset, both from 1
+func position(data []byte, offset int64) (int, int) {
+	before := data[:min(int(offset), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	return line, len(before) - bytes.LastIndexByte(before, '\n')
+}
+
+// Board builds the board the file describes. Pre-placed buildings and roads
+// are checked against it but only placed by NewCatanGameFromFile, since they
+// need players.
+func (f *BoardFile) Board() (*Board, error) {
+	if len(f.Tiles) == 0 {
+		return nil, errors.New("tiles: the board needs at least one tile")
+	}
+	entries := make(map[HexCoord]TileEntry, len(f.Tiles))
+	shape := make([]HexCoord, 0, len(f.Tiles))
+	for i, entry := range f.Tiles {
+		coord := HexCoord{entry.Q, entry.R}
+		if _, ok := entries[coord]; ok {
+			return nil, fmt.Errorf("tiles[%d]: a tile at q=%d r=%d is already listed", i, entry.Q, entry.R)
+		}
+		if err := checkTile(entry); err != nil {
+			return nil, fmt.Errorf("tiles[%d]: %v", i, err)
+		}
+		entries[coord] = entry
+		shape = append(shape, coord)
+	}
+
+	topology, graph := GenerateTopology(shape)
+	if err := graph.Validate(graph.VertexCount(), graph.EdgeCount(), len(topology.Tiles)); err != nil {
+		return nil, fmt.Errorf("tiles: %v", err)
+	}
+	board := &Board{Ports: make([]Port, 0), Graph: graph, Topology: topology}
+	for i, coord := range topology.Tiles {
+		entry := entries[coord]
+		board.Tiles = append(board.Tiles, &Tile{ID: TileID(i + 1), Coord: coord, Resource: entry.Resource, NumberToken: entry.Number})
+		if entry.Resource == "D" && board.RobberPosition == 0 {
+			board.RobberPosition = TileID(i + 1)
+		}
+	}
+	indexTerrain(board)
+
+	if f.Robber != nil {
+		board.RobberPosition = 0
+		for _, tile := range board.Tiles {
+			if tile.Coord == (HexCoord{f.Robber.Q, f.Robber.R}) {
+				board.RobberPosition = tile.ID
+			}
+		}
+		if board.RobberPosition == 0 {
+			return nil, fmt.Errorf("robber: there is no tile at q=%d r=%d", f.Robber.Q, f.Robber.R)
+		}
+		if board.Tiles[board.RobberPosition-1].Resource == Sea {
+			return nil, fmt.Errorf("robber: the tile at q=%d r=%d is sea", f.Robber.Q, f.Robber.R)
+		}
+	} else if board.RobberPosition == 0 {
+		return nil, errors.New("robber: there is no desert, so the robber's starting tile must be given")
+	}
+
+	for i, entry := range f.Ports {
+		if entry.Resource != "A" && !isResource(entry.Resource) {
+			return nil, fmt.Errorf("ports[%d]: unknown resource %q, expected A or one of %v", i, entry.Resource, ResourceTypes)
+		}
+		if err := addPort(board, entry.Resource, entry.Vertices); err != nil {
+			return nil, fmt.Errorf("ports[%d]: %v", i, err)
+		}
+	}
+
+	if f.IslandBonus < 0 {
+		return nil, fmt.Errorf("islandBonus: %d is negative", f.IslandBonus)
+	}
+	if err := f.checkPieces(board); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+func checkTile(entry TileEntry) error {
+	if entry.Resource == "D" || entry.Resource == Sea {
+		if entry.Number != 0 {
+			return fmt.Errorf("a %s tile cannot have number %d", entry.Resource, entry.Number)
+		}
+		return nil
+	}
+	if !isResource(entry.Resource) {
+		return fmt.Errorf("unknown resource %q, expected D, %s or one of %v", entry.Resource, Sea, ResourceTypes)
+	}
+	if entry.Number < 2 || entry.Number > 12 || entry.Number == 7 {
+		return fmt.Errorf("number %d is not one of 2-6 or 8-12", entry.Number)
+	}
+	return nil
+}
+
+func isResource(resource string) bool {
+	for _, r := range ResourceTypes {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Buildings must be on land, one per vertex, and keep the distance rule;
+// roads must be next to land and ships next to the sea, one piece per edge
+func (f *BoardFile) checkPieces(board *Board) error {
+	graph := board.Graph
+	buildingAt := make(map[int]int) // vertex -> building index
+	for i, entry := range f.Buildings {
+		if entry.Player < 1 {
+			return fmt.Errorf("buildings[%d]: player %d is not a player ID", i, entry.Player)
+		}
+		if _, ok := buildingTypes[entry.Type]; !ok {
+			return fmt.Errorf("buildings[%d]: unknown type %q, expected settlement or city", i, entry.Type)
+		}
+		if entry.Vertex < 1 || entry.Vertex > graph.VertexCount() {
+			return fmt.Errorf("buildings[%d]: vertex %d is not on the board, which has vertices 1-%d", i, entry.Vertex, graph.VertexCount())
+		}
+		if VertexIsland(board, entry.Vertex) < 0 {
+			return fmt.Errorf("buildings[%d]: vertex %d is out at sea", i, entry.Vertex)
+		}
+		if other, ok := buildingAt[entry.Vertex]; ok {
+			return fmt.Errorf("buildings[%d]: vertex %d already has buildings[%d]", i, entry.Vertex, other)
+		}
+		for _, adjID := range graph.Neighbors(entry.Vertex) {
+			if other, ok := buildingAt[adjID]; ok {
+				return fmt.Errorf("buildings[%d]: vertex %d is next to buildings[%d] on vertex %d", i, entry.Vertex, other, adjID)
+			}
+		}
+		buildingAt[entry.Vertex] = i
+	}
+
+	pieceAt := make(map[int]string) // edge -> entry, like "roads[2]"
+	for _, list := range []struct {
+		name    string
+		entries []RoadEntry
+		fits    func(*Board, int) bool
+		needs   string
+	}{
+		{"roads", f.Roads, landEdge, "land"},
+		{"ships", f.Ships, seaEdge, "the sea"},
+	} {
+		for i, entry := range list.entries {
+			if entry.Player < 1 {
+				return fmt.Errorf("%s[%d]: player %d is not a player ID", list.name, i, entry.Player)
+			}
+			edge := graph.EdgeBetween(entry.Vertices[0], entry.Vertices[1])
+			if edge < 0 {
+				return fmt.Errorf("%s[%d]: vertices %d and %d are not joined by an edge", list.name, i, entry.Vertices[0], entry.Vertices[1])
+			}
+			if other, ok := pieceAt[edge]; ok {
+				return fmt.Errorf("%s[%d]: edge %s already has %s", list.name, i, EdgeKey(entry.Vertices[0], entry.Vertices[1]), other)
+			}
+			if !list.fits(board, edge) {
+				return fmt.Errorf("%s[%d]: edge %s does not touch %s", list.name, i, EdgeKey(entry.Vertices[0], entry.Vertices[1]), list.needs)
+			}
+			pieceAt[edge] = fmt.Sprintf("%s[%d]", list.name, i)
+		}
+	}
+	return nil
+}
+
+// NewCatanGameFromFile starts a game on the file's board, with its buildings
+// and roads already placed. The bank and development cards are those of the
+// standard board for that many players.
+func NewCatanGameFromFile(playerIDs []int, file *BoardFile, seed int64) (*CatanGame, error) {
+	board, err := file.Board()
+	if err != nil {
+		return nil, err
+	}
+	game := NewSeededCatanGame(playerIDs, seed)
+	game.Board = board
+	game.IslandBonus = file.IslandBonus
+
+	for i, entry := range file.Buildings {
+		player := GetPlayerByID(game, entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("buildings[%d]: there is no player %d", i, entry.Player)
+		}
+		PlaceSettlement(entry.Vertex, player, game)
+		if entry.Type == "city" {
+			PlaceCity(entry.Vertex, player, game)
+		}
+	}
+	for i, entry := range file.Roads {
+		player := GetPlayerByID(game, entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("roads[%d]: there is no player %d", i, entry.Player)
+		}
+		PlaceRoad(entry.Vertices[0], entry.Vertices[1], player, game)
+	}
+	for i, entry := range file.Ships {
+		player := GetPlayerByID(game, entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("ships[%d]: there is no player %d", i, entry.Player)
+		}
+		PlaceShip(entry.Vertices[0], entry.Vertices[1], player, game)
+	}
+	updateLongestRoad(game)
+	return game, nil
+}
+
+// BoardFileFrom describes a game's board and pieces, ready to be saved and
+// loaded again
+func BoardFileFrom(game *CatanGame, name string) *BoardFile {
+	board := game.Board
+	file := &BoardFile{Name: name, IslandBonus: game.IslandBonus}
+	for _, tile := range board.Tiles {
+		file.Tiles = append(file.Tiles, TileEntry{Q: tile.Coord.Q, R: tile.Coord.R, Resource: tile.Resource, Number: tile.NumberToken})
+	}
+	robber := board.Tiles[board.RobberPosition-1].Coord
+	file.Robber = &CoordEntry{Q: robber.Q, R: robber.R}
+	for _, port := range board.Ports {
+		file.Ports = append(file.Ports, PortEntry{Resource: port.GiveResource, Vertices: port.VertexIDs})
+	}
+
+	graph := board.Graph
+	for vertexID := 1; vertexID <= graph.VertexCount(); vertexID++ {
+		if owner := VertexOwner(game, vertexID); owner != nil {
+			buildingType := "settlement"
+			if VertexBuilding(game, vertexID) == 2 {
+				buildingType = "city"
+			}
+			file.Buildings = append(file.Buildings, BuildingEntry{Player: owner.ID, Vertex: vertexID, Type: buildingType})
+		}
+	}
+	for _, road := range Roads(game) {
+		file.Roads = append(file.Roads, RoadEntry{Player: road.OccupiedBy.ID, Vertices: [2]int{road.Vertices[0].ID, road.Vertices[1].ID}})
+	}
+	for _, ship := range Ships(game) {
+		file.Ships = append(file.Ships, RoadEntry{Player: ship.OccupiedBy.ID, Vertices: [2]int{ship.Vertices[0].ID, ship.Vertices[1].ID}})
+	}
+	return file
+}
+
+// Save writes the file as indented JSON
+func (f *BoardFile) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
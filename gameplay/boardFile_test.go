@@ -0,0 +1,111 @@
+package gameplay
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const tinyBoard = `{
+  "name": "tiny",
+  "tiles": [
+    {"q": 0, "r": 0, "resource": "W", "number": 6},
+    {"q": 1, "r": 0, "resource": "D"}
+  ],
+  "robber": {"q": 1, "r": 0},
+  "ports": [{"resource": "A", "vertices": [1, 2]}],
+  "buildings": [{"player": 1, "vertex": 4, "type": "city"}],
+  "roads": [{"player": 1, "vertices": [4, 5]}]
+}`
+
+func TestBoardFileExample(t *testing.T) {
+	file, err := ParseBoardFile([]byte(tinyBoard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	game, err := NewCatanGameFromFile([]int{1, 2, 3}, file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if VertexBuilding(game, 4) != 2 || RoadOwner(game, 4, 5) != game.Players[0] || game.Players[0].VictoryPoints != 2 {
+		t.Error("pre-placed city and road missing")
+	}
+	if game.Board.Tiles[game.Board.RobberPosition-1].Resource != "D" {
+		t.Error("robber should start on the desert")
+	}
+}
+
+// A game saved part way through loads back with the same board and pieces
+func TestBoardFileRoundTrip(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 7)
+	rng := rand.New(rand.NewSource(7))
+	BeginSetup(game, game.Players[0])
+	for game.Phase == "setup" {
+		applyAction(game, LegalActions(game)[0], rng)
+	}
+
+	data, err := json.Marshal(BoardFileFrom(game, "saved"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := ParseBoardFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := NewCatanGameFromFile([]int{1, 2, 3}, file, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tile := range game.Board.Tiles {
+		if *tile != *loaded.Board.Tiles[i] {
+			t.Errorf("tile %d is %v, expected %v", tile.ID, *loaded.Board.Tiles[i], *tile)
+		}
+	}
+	for id := 1; id <= game.Board.Graph.VertexCount(); id++ {
+		if VertexOwner(game, id) != nil && VertexOwner(loaded, id).ID != VertexOwner(game, id).ID {
+			t.Errorf("vertex %d changed owner", id)
+		}
+	}
+	if len(Roads(loaded)) != len(Roads(game)) || len(loaded.Board.Ports) != len(game.Board.Ports) {
+		t.Error("roads or ports were lost")
+	}
+}
+
+func TestBoardFileErrors(t *testing.T) {
+	tests := []struct {
+		edit func(f *BoardFile)
+		want string
+	}{
+		{func(f *BoardFile) { f.Tiles[1].Resource = "X" }, `tiles[1]: unknown resource "X"`},
+		{func(f *BoardFile) { f.Tiles[0].Number = 7 }, "tiles[0]: number 7"},
+		{func(f *BoardFile) { f.Tiles[1].Q, f.Tiles[1].R = 0, 0 }, "tiles[1]: a tile at q=0 r=0 is already listed"},
+		{func(f *BoardFile) { f.Robber = &CoordEntry{Q: 5, R: 5} }, "robber: there is no tile"},
+		{func(f *BoardFile) { f.Ports[0].Vertices = [2]int{1, 3} }, "ports[0]: vertices 1 and 3 are not joined"},
+		{func(f *BoardFile) { f.Ports[0].Vertices = [2]int{3, 8} }, "ports[0]: edge 3-8 is not on the coast"},
+		{func(f *BoardFile) { f.Buildings[0].Vertex = 99 }, "buildings[0]: vertex 99 is not on the board"},
+		{func(f *BoardFile) {
+			f.Buildings = append(f.Buildings, BuildingEntry{Player: 2, Vertex: 5, Type: "settlement"})
+		}, "buildings[1]: vertex 5 is next to buildings[0]"},
+		{func(f *BoardFile) { f.Roads[0].Vertices = [2]int{4, 9} }, "roads[0]: vertices 4 and 9 are not joined"},
+	}
+	for _, test := range tests {
+		var file BoardFile
+		if err := json.Unmarshal([]byte(tinyBoard), &file); err != nil {
+			t.Fatal(err)
+		}
+		test.edit(&file)
+		data, _ := json.Marshal(file)
+		if _, err := ParseBoardFile(data); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("got error %v, expected %q", err, test.want)
+		}
+	}
+
+	if _, err := ParseBoardFile([]byte("{\n  \"tiles\": [\n    {\"q\": 0,, }\n  ]\n}")); err == nil || !strings.HasPrefix(err.Error(), "line 3 column") {
+		t.Errorf("got error %v, expected the line of the syntax error", err)
+	}
+	if _, err := ParseBoardFile([]byte(`{"tiles": [], "colour": "red"}`)); err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Errorf("got error %v, expected the unknown field", err)
+	}
+}
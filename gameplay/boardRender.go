@@ -0,0 +1,298 @@
+// boardRender.go
+
+// Drawing the board as text. Everything is placed from the lattice in hex.go:
+// a vertex at (X, Y) is drawn at column 6X and line 2Y, so each tile is a
+// hexagon 12 columns wide and 8 lines tall. Vertices show their ID, with the
+// building and its owner once something is built there. Edges are drawn
+// between their vertices, and a road or ship on one shows its owner's number.
+// Tiles show their ID, resource, number token and the robber, and ports are
+// labelled just off the coast they are on.
+//
+// When a player has to choose where to build, the places they can choose are
+// drawn highlighted with their number in the menu, like (3), instead.
+package gameplay
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	columnsPerX = 6 // Columns between lattice X positions
+	linesPerY   = 2 // Lines between lattice Y positions
+)
+
+var resourceWords = map[string]string{
+	"B": "Brick",
+	"L": "Lumber",
+	"S": "Sheep",
+	"W": "Wheat",
+	"O": "Ore",
+	"D": "Desert",
+	Sea: "~~~~~",
+}
+
+const highlightColor = "\033[7m" // Reverse video
+
+// A character on the board, in a player's colour if color is set
+type boardCell struct {
+	char      rune
+	color     string
+	highlight bool
+}
+
+// The board is drawn in two steps: text is placed at positions that may be
+// negative, then the smallest grid holding it all is printed
+type boardCanvas struct {
+	cells map[[2]int]boardCell // Keyed by line then column
+}
+
+func (c *boardCanvas) put(line, column int, text string, color string) {
+	for i, char := range []rune(text) {
+		c.cells[[2]int{line, column + i}] = boardCell{char: char, color: color}
+	}
+}
+
+// Places a highlighted menu number centred on a column
+func (c *boardCanvas) mark(line, column int, number string) {
+	text := []rune("(" + number + ")")
+	start := column - len(text)/2
+	for i, char := range text {
+		c.cells[[2]int{line, start + i}] = boardCell{char: char, highlight: true}
+	}
+}
+
+// Places text centred on a column
+func (c *boardCanvas) centre(line, column int, text string, color string) {
+	c.put(line, column-len([]rune(text))/2, text, color)
+}
+
+// Places centred text only if none of its space is taken
+func (c *boardCanvas) centreIfFree(line, column int, text string) {
+	start := column - len([]rune(text))/2
+	for i := range []rune(text) {
+		if _, taken := c.cells[[2]int{line, start + i}]; taken {
+			return
+		}
+	}
+	c.put(line, start, text, "")
+}
+
+func (c *boardCanvas) String(color bool) string {
+	if len(c.cells) == 0 {
+		return ""
+	}
+	first := true
+	var top, bottom, left, right int
+	for position := range c.cells {
+		if first {
+			top, bottom, left, right = position[0], position[0], position[1], position[1]
+			first = false
+		}
+		top, bottom = min(top, position[0]), max(bottom, position[0])
+		left, right = min(left, position[1]), max(right, position[1])
+	}
+
+	var out strings.Builder
+	for line := top; line <= bottom; line++ {
+		var row strings.Builder
+		style := ""
+		for column := left; column <= right; column++ {
+			cell, ok := c.cells[[2]int{line, column}]
+			cellStyle := ""
+			switch {
+			case !ok:
+				cell.char = ' '
+			case !color:
+			case cell.highlight:
+				cellStyle = highlightColor
+			default:
+				cellStyle = cell.color
+			}
+			if cellStyle != style {
+				if style != "" {
+					row.WriteString(resetColor)
+				}
+				row.WriteString(cellStyle)
+				style = cellStyle
+			}
+			row.WriteRune(cell.char)
+		}
+		if style != "" {
+			row.WriteString(resetColor)
+		}
+		out.WriteString(strings.TrimRight(row.String(), " "))
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+func vertexPlace(board *Board, vertexID int) (int, int) {
+	pos := board.Topology.Vertices[vertexID-1]
+	return pos.Y * linesPerY, pos.X * columnsPerX
+}
+
+// RenderBoard draws the board and everything on it, with owners in their
+// colours if color is set
+func RenderBoard(game *CatanGame, color bool) string {
+	return drawBoard(game, nil).String(color)
+}
+
+// RenderChoices draws the board with the vertex or edge of each action
+// marked with its place in the list, counting from 1. Actions sharing an
+// edge, like a road and a ship, share a mark such as (1/2).
+func RenderChoices(game *CatanGame, choices []Action, color bool) string {
+	marks := make(map[[2]int]string) // Keyed by vertex IDs, the second 0 for a vertex
+	for i, action := range choices {
+		place := [2]int{action.VertexID, action.VertexID2}
+		if marks[place] != "" {
+			marks[place] += "/"
+		}
+		marks[place] += fmt.Sprint(i + 1)
+	}
+	return drawBoard(game, marks).String(color)
+}
+
+func drawBoard(game *CatanGame, marks map[[2]int]string) *boardCanvas {
+	board := game.Board
+	graph := board.Graph
+	canvas := &boardCanvas{cells: make(map[[2]int]boardCell)}
+
+	for edge := 0; edge < graph.EdgeCount(); edge++ {
+		ship := graph.shipOwners[edge] != 0
+		slot := graph.edgeOwners[edge]
+		if ship {
+			slot = graph.shipOwners[edge]
+		}
+		color, mark := "", ""
+		if owner := slotPlayer(game, slot); owner != nil {
+			color, mark = playerColor(owner), fmt.Sprint(owner.ID)
+		}
+		drawEdge(canvas, board, edge, mark, ship, color)
+		if a, b := graph.EdgeVertices(edge); marks[[2]int{a, b}] != "" {
+			line1, column1 := vertexPlace(board, a)
+			line2, column2 := vertexPlace(board, b)
+			canvas.mark((line1+line2)/2, (column1+column2)/2, marks[[2]int{a, b}])
+		}
+	}
+	for id := 1; id <= graph.VertexCount(); id++ {
+		line, column := vertexPlace(board, id)
+		label, color := fmt.Sprint(id), ""
+		if player := VertexOwner(game, id); player != nil {
+			color = playerColor(player)
+			label += fmt.Sprintf(":%s%d", map[int]string{1: "S", 2: "C"}[VertexBuilding(game, id)], player.ID)
+		}
+		if marks[[2]int{id, 0}] != "" {
+			canvas.mark(line, column, marks[[2]int{id, 0}])
+		} else {
+			canvas.centre(line, column, label, color)
+		}
+	}
+	for _, port := range board.Ports {
+		drawPort(canvas, board, port)
+	}
+	// Tiles go last, so port labels out at sea can take the place of the waves
+	for _, tile := range board.Tiles {
+		drawTile(canvas, game, tile)
+	}
+	return canvas
+}
+
+// The tile's ID, resource, and number token with the robber next to it
+func drawTile(canvas *boardCanvas, game *CatanGame, tile *Tile) {
+	coord := tile.Coord
+	line, column := 3*coord.R*linesPerY, (2*coord.Q+coord.R)*columnsPerX
+	if tile.Resource == Sea {
+		canvas.centreIfFree(line-1, column, fmt.Sprintf("#%d", tile.ID))
+		canvas.centreIfFree(line, column, resourceWords[Sea])
+		return
+	}
+
+	canvas.centre(line-1, column, fmt.Sprintf("#%d", tile.ID), "")
+	resource := resourceWords[tile.Resource]
+	if resource == "" {
+		resource = tile.Resource
+	}
+	canvas.centre(line, column, resource, "")
+
+	token := ""
+	if tile.NumberToken > 0 {
+		token = fmt.Sprint(tile.NumberToken)
+	}
+	if game.Board.RobberPosition == tile.ID {
+		token = strings.TrimSpace(token + " R")
+	}
+	canvas.centre(line+1, column, token, "")
+}
+
+// Sloping edges are drawn on the line between their ends, upright ones on
+// the three lines between theirs. An empty edge is just its line, and a road
+// or ship puts its owner's number in the middle.
+func drawEdge(canvas *boardCanvas, board *Board, edge int, mark string, ship bool, color string) {
+	a, b := board.Graph.EdgeVertices(edge)
+	line1, column1 := vertexPlace(board, a)
+	line2, column2 := vertexPlace(board, b)
+	if line1 > line2 {
+		line1, column1, line2, column2 = line2, column2, line1, column1
+	}
+	line, column := (line1+line2)/2, (column1+column2)/2
+
+	if column1 == column2 {
+		char := "|"
+		if ship {
+			char = "~"
+		}
+		canvas.put(line-1, column, char, color)
+		canvas.put(line+1, column, char, color)
+		if mark == "" {
+			mark = "|"
+		}
+		canvas.put(line, column, mark, color)
+		return
+	}
+
+	char := "/"
+	if column2 > column1 {
+		char = "\\"
+	}
+	if mark == "" {
+		canvas.put(line, column, char, "")
+		return
+	}
+	if ship {
+		char = "~"
+	}
+	canvas.centre(line, column, char+mark+char, color)
+}
+
+// Ports are labelled just beyond the middle of their edge, out to sea
+func drawPort(canvas *boardCanvas, board *Board, port Port) {
+	line1, column1 := vertexPlace(board, port.VertexIDs[0])
+	line2, column2 := vertexPlace(board, port.VertexIDs[1])
+	line, column := (line1+line2)/2, (column1+column2)/2
+	out := hexDirections[port.Facing]
+	outLines, outColumns := sign(out.R), sign(2*out.Q+out.R)
+
+	label := "3:1"
+	if port.GiveResource != "A" {
+		label = "2:1 " + port.GiveResource
+	}
+	switch {
+	case outLines != 0:
+		canvas.centre(line+2*outLines, column+3*outColumns, label, "")
+	case outColumns > 0:
+		canvas.put(line, column+2, label, "")
+	default:
+		canvas.put(line, column-1-len(label), label, "")
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
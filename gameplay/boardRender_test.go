@@ -0,0 +1,108 @@
+package gameplay
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Finds where text is drawn, as a line and the column of its first character
+func findText(t *testing.T, lines []string, pattern string) (int, int) {
+	t.Helper()
+	re := regexp.MustCompile(pattern)
+	for i, line := range lines {
+		if loc := re.FindStringIndex(line); loc != nil {
+			return i, loc[0]
+		}
+	}
+	t.Fatalf("%q is not on the board", pattern)
+	return 0, 0
+}
+
+func TestRenderBoard(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	vertex := 10
+	PlaceSettlement(vertex, game.Players[1], game)
+	PlaceRoad(vertex, game.Board.Graph.Neighbors(vertex)[0], game.Players[1], game)
+	text := RenderBoard(game, false)
+	lines := strings.Split(text, "\n")
+
+	for _, tile := range game.Board.Tiles {
+		if !strings.Contains(text, fmt.Sprintf("#%d ", tile.ID)) {
+			t.Errorf("tile %d is not drawn", tile.ID)
+		}
+	}
+	if strings.Count(text, resourceWords["D"]) != 1 || strings.Contains(text, " 0 ") {
+		t.Error("the desert should be drawn once, without a number")
+	}
+	if strings.Count(text, "3:1")+strings.Count(text, "2:1") != len(game.Board.Ports) {
+		t.Errorf("expected %d port labels", len(game.Board.Ports))
+	}
+
+	// Vertices are drawn where the lattice puts them: along a row of the
+	// zigzag, each is half a tile further east and up or down a step
+	row := game.Board.Topology.VertexRows[2]
+	line, column := findText(t, lines, fmt.Sprintf(`\b%d\b`, row[0]))
+	for i, id := range row[1:] {
+		step := game.Board.Topology.Vertices[id-1].Y - game.Board.Topology.Vertices[row[0]-1].Y
+		want := line + step*linesPerY
+		label := fmt.Sprint(id)
+		start := column + columnsPerX*(i+1) - len(label)/2 + len(fmt.Sprint(row[0]))/2
+		if want < 0 || want >= len(lines) || !strings.HasPrefix(lines[want][min(start, len(lines[want])):], label) {
+			t.Errorf("vertex %d is not at line %d column %d", id, want, start)
+		}
+	}
+
+	findText(t, lines, fmt.Sprintf(`\b%d:S2\b`, vertex))
+	if !regexp.MustCompile(`[/\\|]2[/\\|]?`).MatchString(text) {
+		t.Error("player 2's road is not drawn")
+	}
+	robber := GetTileByID(game, game.Board.RobberPosition)
+	robberLine, _ := findText(t, lines, `\bR\b`)
+	tileLine, _ := findText(t, lines, fmt.Sprintf(`#%d\b`, robber.ID))
+	if robberLine != tileLine+2 {
+		t.Errorf("the robber is on line %d, expected it under tile %d on line %d", robberLine, robber.ID, tileLine)
+	}
+
+	if colored := RenderBoard(game, true); !strings.Contains(colored, colorText(fmt.Sprintf("%d:S2", vertex), game.Players[1])) {
+		t.Error("player 2's settlement is not in their colour")
+	}
+}
+
+// Each choice is marked once on the board, by its number, in place of the
+// vertex ID or edge line
+func TestRenderChoices(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2}, 1)
+	BeginSetup(game, game.Players[0])
+	settlements := LegalActions(game)
+	text := RenderChoices(game, settlements, false)
+	for i, action := range settlements {
+		if strings.Count(text, fmt.Sprintf("(%d)", i+1)) != 1 {
+			t.Errorf("choice %d, %s, is not marked once", i+1, action)
+		}
+	}
+
+	// Roads from the settlement are marked half way along their edge, found
+	// from where the settlement is drawn
+	settlement := settlements[0].VertexID
+	applyAction(game, settlements[0], nil)
+	roads := LegalActions(game)
+	lines := strings.Split(RenderChoices(game, roads, false), "\n")
+	label := fmt.Sprintf("%d:S1", settlement)
+	foundLine, foundColumn := findText(t, lines, label)
+	placeLine, placeColumn := vertexPlace(game.Board, settlement)
+	for i, action := range roads {
+		line1, column1 := vertexPlace(game.Board, action.VertexID)
+		line2, column2 := vertexPlace(game.Board, action.VertexID2)
+		line := (line1+line2)/2 - placeLine + foundLine
+		column := (column1+column2)/2 - placeColumn + foundColumn + len(label)/2 - 1
+		if mark := fmt.Sprintf("(%d)", i+1); !strings.HasPrefix(lines[line][column:], mark) {
+			t.Errorf("%s is not marked %s half way along it", action, mark)
+		}
+	}
+
+	if colored := RenderChoices(game, roads, true); !strings.Contains(colored, highlightColor+"(") {
+		t.Error("choices are not highlighted")
+	}
+}
@@ -0,0 +1,158 @@
+// boards.go
+
+// The boards a game can be played on. A BoardSpec lists what goes into the box
+// for that board; GenerateBoard shuffles it onto the board's shape.
+package gameplay
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoardLayout is how the tiles and numbers are put on the board
+type BoardLayout string
+
+const (
+	LayoutRandom BoardLayout = "random" // Tiles and numbers both shuffled
+	LayoutSpiral BoardLayout = "spiral" // Tiles shuffled, SpiralTokens laid in a spiral from a random corner
+	LayoutFixed  BoardLayout = "fixed"  // Resources, Tokens and Ports exactly as listed, in tile ID order
+)
+
+type BoardSpec struct {
+	Name          string
+	Layout        BoardLayout // LayoutRandom if empty
+	MinPlayers    int
+	MaxPlayers    int
+	Shape         []HexCoord
+	SeaTiles      []HexCoord // Tiles of Shape that are sea, see seafarers.go
+	Resources     []string   // One per land tile, "D" for desert
+	Tokens        []int      // One per land tile but the desert
+	SpiralTokens  []int      // Tokens in the letter order printed on them, for LayoutSpiral
+	Ports         []string   // "A" for 3:1, otherwise the resource of a 2:1 port
+	PortPositions [][2]int   // Vertex pairs, nil to space ports evenly along the home island's coast
+	BankResources int        // Cards of each resource in the bank
+	DevCards      map[string]int
+	IslandBonus   int // Points for a player's first settlement on each island after setup
+
+	// Between turns every other player may build, see the "special" phase in playerActions.go
+	SpecialBuildPhase bool
+
+	Constraints BoardConstraints // Rules the shuffled board must follow, see boardBalance.go
+}
+
+var BaseBoard = BoardSpec{
+	Name:       "base",
+	MinPlayers: 2, // With neutral players, see twoPlayer.go
+	MaxPlayers: 4,
+	Shape:      HexagonShape(2),
+	Resources:  []string{"W", "W", "W", "W", "L", "L", "L", "L", "O", "O", "O", "B", "B", "B", "S", "S", "S", "S", "D"},
+	Tokens:     []int{2, 3, 3, 4, 4, 5, 5, 6, 6, 8, 8, 9, 9, 10, 10, 11, 11, 12},
+	// A to R
+	SpiralTokens: []int{5, 2, 6, 3, 8, 10, 9, 12, 11, 4, 8, 10, 9, 4, 5, 6, 3, 11},
+	Ports:        []string{"A", "A", "A", "A", "B", "O", "S", "W", "L"},
+	// The official frame: walking clockwise round the coast from the
+	// north-west side of tile 1, ports are 2, 3, 2, 2, 3, 2, 2, 3 and 2
+	// coastal edges apart
+	PortPositions: [][2]int{
+		{1, 2},
+		{4, 5},
+		{15, 16},
+		{27, 38},
+		{46, 47},
+		{51, 52},
+		{48, 49},
+		{29, 39},
+		{8, 18},
+	},
+	BankResources: 19,
+	DevCards: map[string]int{
+		"Knight":         14,
+		"Victory Point":  5,
+		"Road Building":  2,
+		"Year of Plenty": 2,
+		"Monopoly":       2,
+	},
+}
+
+// BeginnerBoard is the starting set-up printed in the base game rules, row by
+// row from the top
+var BeginnerBoard = func() BoardSpec {
+	spec := BaseBoard
+	spec.Name = "beginner"
+	spec.Layout = LayoutFixed
+	spec.Resources = []string{
+		"O", "S", "L",
+		"W", "B", "S", "B",
+		"W", "L", "D", "L", "O",
+		"L", "O", "W", "S",
+		"B", "W", "S",
+	}
+	spec.Tokens = []int{
+		10, 2, 9,
+		12, 6, 4, 10,
+		9, 11, 3, 8,
+		8, 3, 4, 5,
+		5, 6, 11,
+	}
+	return spec
+}()
+
+// ExtensionBoard is the 5-6 player extension: 30 tiles in rows of
+// 3-4-5-6-5-4-3, two more ports, a bigger bank and deck, and the special
+// building phase
+var ExtensionBoard = BoardSpec{
+	Name:       "extension",
+	MinPlayers: 5,
+	MaxPlayers: 6,
+	Shape:      RowsShape(3, 4, 5, 6, 5, 4, 3),
+	Resources: []string{
+		"W", "W", "W", "W", "W", "W", "L", "L", "L", "L", "L", "L", "S", "S", "S", "S", "S", "S",
+		"O", "O", "O", "O", "O", "B", "B", "B", "B", "B", "D", "D",
+	},
+	Tokens: []int{
+		2, 2, 3, 3, 3, 4, 4, 4, 5, 5, 5, 6, 6, 6,
+		8, 8, 8, 9, 9, 9, 10, 10, 10, 11, 11, 11, 12, 12,
+	},
+	Ports:         []string{"A", "A", "A", "A", "A", "B", "O", "S", "S", "W", "L"},
+	BankResources: 24,
+	DevCards: map[string]int{
+		"Knight":         20,
+		"Victory Point":  5,
+		"Road Building":  3,
+		"Year of Plenty": 3,
+		"Monopoly":       3,
+	},
+	SpecialBuildPhase: true,
+}
+
+// Boards lists every board that can be picked by name
+var Boards = []BoardSpec{BaseBoard, BeginnerBoard, ExtensionBoard, SeafarersBoard}
+
+// BoardSpecNamed looks a board up by its Name
+func BoardSpecNamed(name string) (BoardSpec, error) {
+	var names []string
+	for _, spec := range Boards {
+		if spec.Name == name {
+			return spec, nil
+		}
+		names = append(names, spec.Name)
+	}
+	return BoardSpec{}, fmt.Errorf("unknown board %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// ParseBoardLayout reads a layout by its name
+func ParseBoardLayout(name string) (BoardLayout, error) {
+	switch layout := BoardLayout(name); layout {
+	case LayoutRandom, LayoutSpiral, LayoutFixed:
+		return layout, nil
+	}
+	return "", fmt.Errorf("unknown layout %q, expected %s, %s or %s", name, LayoutRandom, LayoutSpiral, LayoutFixed)
+}
+
+// BoardSpecFor picks the board for a number of players
+func BoardSpecFor(players int) BoardSpec {
+	if players > BaseBoard.MaxPlayers {
+		return ExtensionBoard
+	}
+	return BaseBoard
+}
@@ -0,0 +1,155 @@
+package gameplay
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExtensionBoard(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3, 4, 5, 6}, 1)
+	if err := game.Board.Graph.Validate(80, 109, 30); err != nil {
+		t.Fatal(err)
+	}
+	if len(game.Board.Ports) != 11 || !game.SpecialBuild {
+		t.Errorf("got %d ports and special building %v, expected 11 and true", len(game.Board.Ports), game.SpecialBuild)
+	}
+	if len(game.Bank.DevelopmentCards) != 34 || game.Bank.Resources["O"] != 24 {
+		t.Errorf("got %d development cards and %d ore, expected 34 and 24", len(game.Bank.DevelopmentCards), game.Bank.Resources["O"])
+	}
+	for _, port := range game.Board.Ports {
+		if game.Board.Graph.EdgeBetween(port.VertexIDs[0], port.VertexIDs[1]) < 0 {
+			t.Errorf("port %v is not on an edge", port.VertexIDs)
+		}
+	}
+
+	if base := NewSeededCatanGame([]int{1, 2, 3, 4}, 1); base.SpecialBuild || len(base.Board.Tiles) != 19 {
+		t.Error("4 players should get the base board")
+	}
+}
+
+// After player 1's turn players 2 to 6 each get a special build, then player 2
+// starts their turn
+func TestSpecialBuildOrder(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3, 4, 5, 6}, 1)
+	game.Phase = "main"
+	game.HasRolled = true
+
+	var builders []int
+	applyAction(game, Action{Type: ActionEndTurn, PlayerID: 1}, nil)
+	for game.Phase == "special" {
+		builders = append(builders, CurrentPlayer(game).ID)
+		for _, action := range LegalActions(game) {
+			if action.Type == ActionRoll || action.Type == ActionBankTrade || action.Type == ActionPlayKnight {
+				t.Fatalf("%s offered in the special building phase", action)
+			}
+		}
+		applyAction(game, Action{Type: ActionEndTurn, PlayerID: CurrentPlayer(game).ID}, nil)
+	}
+
+	if fmt.Sprint(builders) != "[2 3 4 5 6]" || game.Phase != "main" || CurrentPlayer(game).ID != 2 || game.HasRolled {
+		t.Errorf("special builders %v then %s phase for player %d, expected [2 3 4 5 6] then player 2 to roll",
+			builders, game.Phase, CurrentPlayer(game).ID)
+	}
+}
+
+func TestFairBoardConstraints(t *testing.T) {
+	for _, spec := range []BoardSpec{BaseBoard, ExtensionBoard} {
+		spec.Constraints = FairBoard
+		for seed := int64(1); seed <= 10; seed++ {
+			game, err := NewCatanGameOnBoard([]int{1, 2, 3}, spec, seed)
+			if err != nil {
+				t.Fatalf("%s board, seed %d: %v", spec.Name, seed, err)
+			}
+			if err := FairBoard.Check(game.Board); err != nil {
+				t.Errorf("%s board, seed %d: %v", spec.Name, seed, err)
+			}
+			// Every tile but the desert still has a number
+			for _, tile := range game.Board.Tiles {
+				if (tile.Resource == "D") != (tile.NumberToken == 0) {
+					t.Errorf("%s board, seed %d: tile %d is %s with number %d", spec.Name, seed, tile.ID, tile.Resource, tile.NumberToken)
+				}
+			}
+		}
+	}
+
+	impossible := BaseBoard
+	impossible.Constraints = BoardConstraints{MaxResourceCluster: 1, MinBalance: 1}
+	if _, err := NewCatanGameOnBoard([]int{1, 2, 3}, impossible, 1); err == nil {
+		t.Error("expected an error for constraints no board can meet")
+	}
+}
+
+func TestBeginnerBoard(t *testing.T) {
+	a, _ := NewCatanGameOnBoard([]int{1, 2, 3}, BeginnerBoard, 1)
+	b, _ := NewCatanGameOnBoard([]int{1, 2, 3}, BeginnerBoard, 2)
+	for i, tile := range a.Board.Tiles {
+		if *tile != *b.Board.Tiles[i] {
+			t.Fatalf("tile %d differs between seeds", tile.ID)
+		}
+	}
+	if desert := a.Board.Tiles[a.Board.RobberPosition-1]; desert.Resource != "D" || desert.Coord != (HexCoord{}) {
+		t.Errorf("robber starts on %s at %v, expected the desert in the middle", desert.Resource, desert.Coord)
+	}
+	if a.Board.Tiles[0].Resource != "O" || a.Board.Tiles[0].NumberToken != 10 {
+		t.Errorf("tile 1 is %s %d, expected O 10", a.Board.Tiles[0].Resource, a.Board.Tiles[0].NumberToken)
+	}
+}
+
+// The spiral visits every tile once, each next to the one before
+func TestSpiralOrder(t *testing.T) {
+	for _, shape := range [][]HexCoord{HexagonShape(2), RowsShape(3, 4, 5, 6, 5, 4, 3)} {
+		for _, corner := range ShapeCorners(shape) {
+			order := SpiralOrder(shape, corner)
+			seen := make(map[HexCoord]bool)
+			for i, tile := range order {
+				if seen[tile] || (i > 0 && tile.Distance(order[i-1]) != 1) {
+					t.Fatalf("spiral from %v breaks at step %d: %v", corner, i, order)
+				}
+				seen[tile] = true
+			}
+			if order[0] != corner || len(order) != len(shape) {
+				t.Errorf("spiral from %v starts at %v and covers %d of %d tiles", corner, order[0], len(order), len(shape))
+			}
+		}
+	}
+}
+
+func TestSpiralTokens(t *testing.T) {
+	spec := BaseBoard
+	spec.Layout = LayoutSpiral
+	game, err := NewCatanGameOnBoard([]int{1, 2, 3}, spec, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting from whichever corner has the A token, the spiral reads A to R
+	byCoord := make(map[HexCoord]*Tile)
+	for _, tile := range game.Board.Tiles {
+		byCoord[tile.Coord] = tile
+	}
+	for _, corner := range ShapeCorners(spec.Shape) {
+		var numbers []int
+		for _, coord := range SpiralOrder(spec.Shape, corner) {
+			if tile := byCoord[coord]; tile.Resource != "D" {
+				numbers = append(numbers, tile.NumberToken)
+			}
+		}
+		if fmt.Sprint(numbers) == fmt.Sprint(spec.SpiralTokens) {
+			return
+		}
+	}
+	t.Error("no corner's spiral reads the tokens in letter order")
+}
+
+func TestParseBoardLayout(t *testing.T) {
+	for _, layout := range []BoardLayout{LayoutRandom, LayoutSpiral, LayoutFixed} {
+		if got, err := ParseBoardLayout(string(layout)); got != layout || err != nil {
+			t.Errorf("%s was read as %q, %v", layout, got, err)
+		}
+	}
+	for _, name := range []string{"", "Spiral", "spirals"} {
+		if _, err := ParseBoardLayout(name); err == nil {
+			t.Errorf("%q was accepted", name)
+		}
+	}
+}
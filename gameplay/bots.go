@@ -0,0 +1,146 @@
+// bots.go
+
+// Simple computer players. Besides playing games on their own they are the
+// rollout policy for MCTSBot, so they need to be cheap.
+package gameplay
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// NewAgent builds a bot from its name: "random", "greedy", or "mcts" with an
+// optional iteration budget such as "mcts:500"
+func NewAgent(name string, rng *rand.Rand) (Agent, error) {
+	kind, arg, hasArg := strings.Cut(name, ":")
+	switch kind {
+	case "random":
+		return &RandomBot{Rand: rng}, nil
+	case "greedy":
+		return &GreedyBot{Rand: rng}, nil
+	case "mcts":
+		iterations := 300
+		if hasArg {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid MCTS iterations %q in %q", arg, name)
+			}
+			iterations = n
+		}
+		return NewMCTSBot(rng, iterations), nil
+	}
+	return nil, fmt.Errorf("unknown agent %q, expected random, greedy or mcts[:iterations]", name)
+}
+
+// RandomBot picks any legal move with equal chance
+type RandomBot struct {
+	Rand *rand.Rand
+}
+
+func (rb *RandomBot) ChooseAction(game *CatanGame, legal []Action) Action {
+	return legal[rb.Rand.Intn(len(legal))]
+}
+
+// GreedyBot always takes the best looking move right now: cities over
+// settlements over development cards over roads, building on the spots that
+// produce the most. It only trades with the bank to afford something.
+// Epsilon is the chance of making a random move instead.
+type GreedyBot struct {
+	Rand    *rand.Rand
+	Epsilon float64
+}
+
+// How much the greedy policy wants each kind of move
+var greedyPriority = map[ActionType]int{
+	ActionBuildCity:        9,
+	ActionBuildSettlement:  8,
+	ActionSetupSettlement:  8,
+	ActionSetupRoad:        8,
+	ActionSetupShip:        8,
+	ActionPlayRoadBuilding: 7,
+	ActionPlayYearOfPlenty: 7,
+	ActionPlayMonopoly:     6,
+	ActionPlayKnight:       5,
+	ActionBuyDevCard:       4,
+	ActionBuildRoad:        3,
+	ActionBuildShip:        3,
+	ActionMoveRobber:       3,
+	ActionBankTrade:        2,
+	ActionRoll:             1,
+	ActionEndTurn:          0,
+}
+
+func (gb *GreedyBot) ChooseAction(game *CatanGame, legal []Action) Action {
+	if gb.Epsilon > 0 && gb.Rand.Float64() < gb.Epsilon {
+		return legal[gb.Rand.Intn(len(legal))]
+	}
+
+	best := legal[0]
+	bestScore := -1
+	for _, action := range legal {
+		score := greedyPriority[action.Type]*1000 + actionValue(game, action)
+		if (action.Type == ActionBankTrade && !tradeHelps(game, action)) || action.Type == ActionMoveShip {
+			score = -1 // Ships are only moved by chance
+		}
+		if (action.Type == ActionBuildRoad || action.Type == ActionBuildShip) && game.FreeRoads == 0 && len(ComputeValidSettlementPlacements(game, CurrentPlayer(game))) > 0 {
+			score = greedyPriority[ActionEndTurn] * 1000 // Save up for the settlement instead
+		}
+		if score > bestScore {
+			best, bestScore = action, score
+		}
+	}
+	return best
+}
+
+// Pips are the number of ways to roll a token with two dice
+func pips(token int) int {
+	if token < 2 || token > 12 || token == 7 {
+		return 0
+	}
+	if token < 7 {
+		return token - 1
+	}
+	return 13 - token
+}
+
+// VertexPips totals the pips of every producing tile around a vertex
+func VertexPips(game *CatanGame, vertexID int) int {
+	total := 0
+	for _, tile := range vertexTiles(game, vertexID) {
+		total += pips(tile.NumberToken)
+	}
+	return total
+}
+
+// Tie breaker between moves of the same kind
+func actionValue(game *CatanGame, action Action) int {
+	switch action.Type {
+	case ActionSetupSettlement, ActionBuildSettlement, ActionBuildCity:
+		return VertexPips(game, action.VertexID)
+	case ActionSetupRoad, ActionBuildRoad, ActionSetupShip, ActionBuildShip:
+		return VertexPips(game, action.VertexID2)
+	case ActionMoveRobber:
+		if action.VictimID == 0 {
+			return 0
+		}
+		return 100 + TotalVictoryPoints(GetPlayerByID(game, action.VictimID))
+	}
+	return 0
+}
+
+// A bank trade is only worth it if it completes the cost of a city or settlement
+func tradeHelps(game *CatanGame, trade Action) bool {
+	player := CurrentPlayer(game)
+	for _, item := range []string{"city", "settlement"} {
+		cost := BuildCosts[item]
+		if cost[trade.Get] <= player.Resources[trade.Get] {
+			continue
+		}
+		if player.Resources[trade.Give]-TradeRatio(game, player, trade.Give) >= cost[trade.Give] {
+			return true
+		}
+	}
+	return false
+}
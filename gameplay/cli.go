@@ -3,214 +3,67 @@ package gameplay
 
 import (
 	"fmt"
-	"strings"
+	"io"
 )
 
-var playerColors = []string{
-	"\033[31m", // Red
-	"\033[32m", // Green
-	"\033[34m", // Blue
-	"\033[33m", // Yellow
+// ColorNames are the colours players can have, in the order they are given
+// to players by ID when not chosen
+var ColorNames = []string{"red", "green", "blue", "yellow", "magenta", "cyan"}
+
+var colorCodes = map[string]string{
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"blue":    "\033[34m",
+	"yellow":  "\033[33m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
 }
 
 const resetColor = "\033[0m"
 
-func colorText(text string, playerID int) string {
-	if playerID <= 0 || playerID > len(playerColors) {
-		return text
+// The escape code for a player's colour, empty if they have none
+func playerColor(player *Player) string {
+	if player == nil {
+		return ""
 	}
-	return playerColors[playerID-1] + text + resetColor
-}
-
-func (cg *CLIGame) readInt(prompt string) int {
-	fmt.Print(prompt)
-	var num int
-	fmt.Fscanln(cg.Input, &num)
-	return num
-}
-
-// Print the raw contents of the board
-func PrintRaw(game *CatanGame) {
-	fmt.Println("===== CATAN GAME STATE =====\n")
-
-	// Print players and their stats
-	fmt.Println("Players:")
-	for _, player := range game.Players {
-		fmt.Printf("Player %d:\n", player.ID)
-		fmt.Printf("  Resources: %v\n", player.Resources)
-		fmt.Printf("  Victory Points: %d\n", player.VictoryPoints)
-		fmt.Printf("  Development Cards: %v\n", player.DevelopmentCards)
+	if player.Color != "" {
+		return colorCodes[player.Color]
 	}
-	fmt.Println()
-
-	// Print vertices ownership
-	fmt.Println("Vertices:")
-	for key, vertex := range game.Board.Graph.Vertices {
-		if vertex.OccupiedBy != nil {
-			fmt.Printf("Vertex ID %s (Struct ID %d): Occupied by Player %d with building type %d\n",
-				key, vertex.ID, vertex.OccupiedBy.ID, vertex.Building)
-		}
+	if player.ID <= 0 || player.ID > len(ColorNames) {
+		return ""
 	}
-	fmt.Println()
+	return colorCodes[ColorNames[player.ID-1]]
+}
 
-	// Print edges ownership
-	fmt.Println("Edges:")
-	for key, edge := range game.Board.Graph.Edges {
-		if edge.OccupiedBy != nil {
-			fmt.Printf("Edge ID %s: Occupied by Player %d (Vertices %d and %d)\n",
-				key, edge.OccupiedBy.ID, edge.Vertices[0].ID, edge.Vertices[1].ID)
-		}
+func colorText(text string, player *Player) string {
+	color := playerColor(player)
+	if color == "" {
+		return text
 	}
-	fmt.Println()
-
-	// Print bank status
-	fmt.Println("Bank:")
-	fmt.Printf("  Resources: %v\n", game.Bank.Resources)
-	fmt.Printf("  Remaining Dev Cards: %d\n", len(game.Bank.DevelopmentCards))
-	fmt.Println()
-
-	// Print turn and phase
-	fmt.Println("Game Info:")
-	fmt.Printf("  Current Turn: Player %d\n", game.Players[game.TurnIndex].ID)
-	fmt.Printf("  Phase: %s\n", game.Phase)
-	fmt.Println("============================\n")
+	return color + text + resetColor
 }
 
+// PrintGameBoard draws the board in colour, see boardRender.go
 func PrintGameBoard(game *CatanGame) {
-	// Tile layout by rows
-	tileRows := [][]int{
-		{1, 2, 3},
-		{4, 5, 6, 7},
-		{8, 9, 10, 11, 12},
-		{13, 14, 15, 16},
-		{17, 18, 19},
-	}
-
-	// Vertex layout by rows
-	vertexRows := [][]int{
-		{1, 2, 3, 4, 5, 6, 7},
-		{8, 9, 10, 11, 12, 13, 14, 15, 16},
-		{17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27},
-		{28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38},
-		{39, 40, 41, 42, 43, 44, 45, 46, 47},
-		{48, 49, 50, 51, 52, 53, 54},
-	}
-
-	// Build port information
-	portInfo := make(map[int]string)
-	for _, port := range game.Board.Ports {
-		for _, vertexID := range port.VertexIDs {
-			portInfo[vertexID] = port.GiveResource
-		}
-	}
-
-	// Print tile rows with vertices between them
-	for i, tileRow := range tileRows {
-		// Print vertex row above tiles (except first row)
-		if i > 0 {
-			printVertexRow(game, vertexRows[i-1], portInfo, i-1)
-		}
-
-		// Print tile row
-		printTileRow(game, tileRow)
-
-		// Print vertex row below tiles (except last row)
-		if i < len(tileRows)-1 {
-			printVertexRow(game, vertexRows[i+1], portInfo, i+1)
-		}
-	}
+	fmt.Print(RenderBoard(game, true))
 }
 
-func printTileRow(game *CatanGame, tileIDs []int) {
-	line1 := ""
-	line2 := ""
-	line3 := ""
-
-	for _, id := range tileIDs {
-		tile := game.Board.Tiles[id-1] // tiles are 1-indexed in display
-		robber := ""
-		if game.Board.RobberPosition == id-1 {
-			robber = "R"
-		}
-
-		// Center the number token with padding
-		numStr := fmt.Sprintf("%d", tile.NumberToken)
-		if tile.NumberToken < 10 {
-			numStr = " " + numStr
-		}
-		if tile.NumberToken == -1 {
-			numStr = " D"
-		}
-
-		line1 += fmt.Sprintf("   /%s\\   ", numStr)
-		line2 += fmt.Sprintf(" /%s %s\\ ", tile.Resource, robber)
-		line3 += fmt.Sprintf("/%s\\ ", strings.Repeat(" ", 5))
+// Lists choices numbered from 1, as many to a line as fit in width
+func printMenu(w io.Writer, game *CatanGame, choices []Action, width int) {
+	entries := make([]string, len(choices))
+	entryWidth := 0
+	for i, action := range choices {
+		entries[i] = fmt.Sprintf("%d) %s", i+1, describeAction(game, action))
+		entryWidth = max(entryWidth, len(entries[i])+2)
 	}
-
-	fmt.Println(line1)
-	fmt.Println(line2)
-	fmt.Println(line3)
-}
-
-// cli print the edges as they appear in the valid edge placements
-func PrintValidEdges() {
-
-}
-
-func printVertexRow(game *CatanGame, vertexIDs []int, portInfo map[int]string, rowType int) {
-	line1 := ""
-	line2 := ""
-
-	for _, id := range vertexIDs {
-		vertex := game.Board.Graph.Vertices[id]
-		player := "0"
-		building := " "
-		port := " "
-
-		if vertex.OccupiedBy != nil {
-			player = fmt.Sprintf("%d", vertex.OccupiedBy.ID)
-			if vertex.Building == 1 {
-				building = "S" // Settlement
-			} else if vertex.Building == 2 {
-				building = "C" // City
-			}
-		}
-
-		if p, exists := portInfo[id]; exists {
-			if p == "A" {
-				port = "3:1"
-			} else {
-				port = p + ":1"
-			}
-		}
-
-		// Alternate between vertex and edge positions based on row type
-		if rowType%2 == 0 {
-			// Vertex row (main vertices)
-			line1 += fmt.Sprintf(" %s%s%s ", port, building, player)
-			line2 += "   "
+	perLine := max(1, width/entryWidth)
+	for i, entry := range entries {
+		if i%perLine == perLine-1 || i == len(entries)-1 {
+			fmt.Fprintln(w, entry)
 		} else {
-			// Edge row (between vertices)
-			// Find edge between this vertex and next
-			edgePlayer := "0"
-			if len(vertex.AdjacentVertexes) > 0 {
-				for _, adjID := range vertex.AdjacentVertexes {
-					edgeKey := fmt.Sprintf("%d-%d", min(id, adjID), max(id, adjID))
-					if edge, exists := game.Board.Graph.Edges[edgeKey]; exists && edge.OccupiedBy != nil {
-						edgePlayer = fmt.Sprintf("%d", edge.OccupiedBy.ID)
-						break
-					}
-				}
-			}
-			line1 += "   "
-			line2 += fmt.Sprintf("  %s  ", edgePlayer)
+			fmt.Fprintf(w, "%-*s", entryWidth, entry)
 		}
 	}
-
-	fmt.Println(line1)
-	if rowType%2 == 1 {
-		fmt.Println(line2)
-	}
 }
 
 func min(a, b int) int {
@@ -0,0 +1,826 @@
+// cliCommands.go
+
+// The command shell players use once setup is over. Each line is a command
+// like "build road 10 11" or its short form "br 10 11"; moves are turned into
+// Actions and checked by the rules engine, and when one is not allowed the
+// player is told why in plain words. Building commands typed without saying
+// where show the board with the places they could go numbered, to pick one.
+// Commands that only show something, like "hand" or "board", can be used at
+// any time.
+package gameplay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cliCommand struct {
+	names []string // Full name first, then the short forms
+	args  string
+	help  string
+}
+
+var cliCommands = []cliCommand{
+	{[]string{"roll", "r"}, "", "roll the dice to start your turn"},
+	{[]string{"build road", "road", "br"}, "[<vertex> <vertex>]", "build a road between two vertices, or choose from the board"},
+	{[]string{"build settlement", "settlement", "settle", "bs"}, "[<vertex>]", "build a settlement"},
+	{[]string{"city", "build city", "c"}, "[<vertex>]", "turn your settlement into a city"},
+	{[]string{"build ship", "ship", "bsh"}, "[<vertex> <vertex>]", "build a ship on an edge next to the sea"},
+	{[]string{"move ship", "ms"}, "[<vertex> <vertex> to <vertex> <vertex>]", "move the ship at the open end of a line"},
+	{[]string{"buy dev", "dev", "bd"}, "", "buy a development card"},
+	{[]string{"play knight", "knight", "k"}, "", "play a knight and move the robber"},
+	{[]string{"play roads", "play road building", "roads"}, "", "play road building for two free roads"},
+	{[]string{"play plenty", "play yop", "yop"}, "<resource> <resource>", "play year of plenty, taking two cards"},
+	{[]string{"play monopoly", "monopoly", "mono"}, "<resource>", "play monopoly, taking every card of a resource"},
+	{[]string{"robber", "rob"}, "[<tile> [player]]", "move the robber, stealing from a player there"},
+	{[]string{"neutral road", "nr"}, "[<vertex> <vertex> [for <player>]]", "build the road owed to a neutral player"},
+	{[]string{"neutral settlement", "ns"}, "[<vertex> [for <player>]]", "build the settlement owed to a neutral player"},
+	{[]string{"force trade", "force", "ft"}, "", "2-player game: pay trade tokens to take 2 random cards from your opponent, then give 2 back"},
+	{[]string{"give back", "gb"}, "<resource>", "give a card back after a forced trade"},
+	{[]string{"return robber", "rr"}, "", "2-player game: pay trade tokens to put the robber back in the desert"},
+	{[]string{"trade bank", "trade", "tb"}, "[amount] <give> <get>", "trade with the bank, like trade bank 4 W O"},
+	{[]string{"offer", "o"}, "<n> <resource> ... for <n> <resource> ... [to <player>]", "offer other players a trade"},
+	{[]string{"end", "e", "done"}, "", "end your turn"},
+	{[]string{"board", "b"}, "", "show the board"},
+	{[]string{"hand", "h"}, "", "show your cards"},
+	{[]string{"score", "s"}, "", "show everyone's points"},
+	{[]string{"history", "hist"}, "[count]", "show the last moves"},
+	{[]string{"save"}, "[file]", "save the game"},
+	{[]string{"quit", "q"}, "", "save the game and stop"},
+	{[]string{"help", "?"}, "", "show this list"},
+}
+
+var resourceNames = map[string]string{
+	"b": "B", "brick": "B",
+	"l": "L", "lumber": "L", "wood": "L",
+	"s": "S", "sheep": "S", "wool": "S",
+	"w": "W", "wheat": "W", "grain": "W",
+	"o": "O", "ore": "O",
+}
+
+// Commands that, typed without saying where, offer a menu of the places
+// they could go
+var placementCommands = map[string]struct {
+	actionType ActionType
+	item       string // What it costs, see BuildCosts
+	what       string
+}{
+	"build road":         {ActionBuildRoad, "road", "build a road"},
+	"build settlement":   {ActionBuildSettlement, "settlement", "build a settlement"},
+	"city":               {ActionBuildCity, "city", "build a city"},
+	"build ship":         {ActionBuildShip, "ship", "build a ship"},
+	"move ship":          {ActionMoveShip, "", "move a ship"},
+	"robber":             {ActionMoveRobber, "", "move the robber"},
+	"neutral road":       {ActionNeutralRoad, "", "build a neutral road"},
+	"neutral settlement": {ActionNeutralSettlement, "", "build a neutral settlement"},
+}
+
+// errQuit stops Play after the game has been saved
+var errQuit = errors.New("quit")
+
+// Play runs the game from the end of setup until someone wins, reading
+// commands for whoever has to act. If input runs out, or a player quits,
+// the game is saved first.
+func (cg *CLIGame) Play(game *CatanGame) error {
+	if cg.Rand == nil {
+		cg.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	cg.watch(game)
+	fmt.Fprintln(cg.out(), "Type help to see the commands.")
+	for game.Phase != "finished" {
+		if cg.botMove(game) {
+			continue
+		}
+		if err := cg.handOver(game); err != nil {
+			return cg.stopped(game, err)
+		}
+		line, err := cg.readLine(cg.prompt(game))
+		if err != nil {
+			return cg.stopped(game, err)
+		}
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if err := cg.runCommand(game, words); err == errQuit {
+			return nil
+		} else if err == ErrInputClosed {
+			return cg.stopped(game, err)
+		} else if err != nil {
+			fmt.Fprintln(cg.out(), err)
+		}
+	}
+	winner := GetPlayerByID(game, game.WinnerID)
+	fmt.Fprintf(cg.out(), "%s wins with %d points!\n", winner, TotalVictoryPoints(winner))
+	return nil
+}
+
+// The prompt says who is acting and, outside the usual turn, what they owe
+func (cg *CLIGame) prompt(game *CatanGame) string {
+	player := CurrentPlayer(game)
+	switch game.Phase {
+	case "robber":
+		return fmt.Sprintf("%s, move the robber (robber <tile> [player])> ", player)
+	case "neutral":
+		return fmt.Sprintf("%s, build a %s for a neutral player (neutral %s ...)> ", player, game.NeutralBuild, game.NeutralBuild)
+	case "forced":
+		return fmt.Sprintf("%s, give back %d cards (give back <resource>)> ", player, game.GiveBack)
+	case "special":
+		return fmt.Sprintf("%s, special building phase> ", player)
+	}
+	if !game.HasRolled {
+		return fmt.Sprintf("%s, roll when ready> ", player)
+	}
+	return fmt.Sprintf("%s> ", player)
+}
+
+// Finds the command the words start with, preferring the longest name, and
+// returns it with the words after its name
+func matchCommand(words []string) (*cliCommand, []string) {
+	var best *cliCommand
+	bestLength := 0
+	for i := range cliCommands {
+		for _, name := range cliCommands[i].names {
+			fields := strings.Fields(name)
+			if len(fields) > len(words) || len(fields) <= bestLength {
+				continue
+			}
+			if strings.EqualFold(strings.Join(words[:len(fields)], " "), name) {
+				best, bestLength = &cliCommands[i], len(fields)
+			}
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best, words[bestLength:]
+}
+
+func (cg *CLIGame) runCommand(game *CatanGame, words []string) error {
+	command, args := matchCommand(words)
+	if command == nil {
+		return fmt.Errorf("%q is not a command, type help to see them", strings.Join(words, " "))
+	}
+	if command.names[0] != "save" { // File names keep their case
+		for i := range args {
+			args[i] = strings.ToLower(args[i])
+		}
+	}
+	player := CurrentPlayer(game)
+
+	switch command.names[0] {
+	case "board":
+		if cg.screen == nil { // The full screen always shows it
+			fmt.Fprint(cg.out(), RenderBoard(game, true))
+		}
+		return nil
+	case "hand":
+		if cg.screen == nil { // The full screen always shows it
+			printHand(cg.out(), player)
+		}
+		return nil
+	case "score":
+		printScores(cg.out(), game, !cg.HotSeat)
+		return nil
+	case "history":
+		count := 10
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%q is not a number of moves", args[0])
+			}
+			count = n
+		}
+		for _, line := range cg.history[max(0, len(cg.history)-count):] {
+			fmt.Fprintln(cg.out(), line)
+		}
+		return nil
+	case "help":
+		printHelp(cg.out())
+		return nil
+	case "save", "quit":
+		path := cg.SavePath
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if path == "" {
+			path = DefaultSavePath
+		}
+		if err := SaveSession(game, cg.Seats, path); err != nil {
+			return fmt.Errorf("the game could not be saved: %v", err)
+		}
+		fmt.Fprintf(cg.out(), "Game saved in %s\n", path)
+		if command.names[0] == "quit" {
+			return errQuit
+		}
+		return nil
+	case "offer":
+		return cg.offerTrade(game, args)
+	}
+
+	if placement, ok := placementCommands[command.names[0]]; ok && len(args) == 0 {
+		return cg.choosePlacement(game, placement.actionType, placement.item, placement.what)
+	}
+
+	action, err := parseAction(game, command.names[0], args)
+	if err != nil {
+		return fmt.Errorf("%v, type it as: %s %s", err, command.names[0], command.args)
+	}
+	if !isLegal(game, action) {
+		return explainIllegal(game, action)
+	}
+	cg.apply(game, action)
+	return nil
+}
+
+// Shows the places an action could go on the board and carries out the one
+// chosen from the menu. Going back cancels it.
+func (cg *CLIGame) choosePlacement(game *CatanGame, actionType ActionType, item, what string) error {
+	var choices []Action
+	for _, action := range LegalActions(game) {
+		if action.Type == actionType {
+			choices = append(choices, action)
+		}
+	}
+	if len(choices) == 0 {
+		if err := explainPhase(game, actionType); err != nil {
+			return err
+		}
+		free := actionType == ActionBuildRoad && game.FreeRoads > 0
+		if item != "" && !free && !CanPlayerAfford(CurrentPlayer(game), item) {
+			return cannotAfford(item)
+		}
+		return fmt.Errorf("there is nowhere you can %s", what)
+	}
+
+	action, err := cg.chooseAction(game, choices, fmt.Sprintf("Choose where to %s, or back to cancel: ", what))
+	if err == ErrCancelled {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cg.apply(game, action)
+	return nil
+}
+
+// Carries out a legal move, telling everyone what happened and keeping it in
+// the history
+func (cg *CLIGame) apply(game *CatanGame, action Action) {
+	player := CurrentPlayer(game)
+	applyAction(game, action, cg.Rand)
+
+	event := fmt.Sprintf("%s: %s", player, describeAction(game, action))
+	if action.Type == ActionRoll {
+		event = fmt.Sprintf("%s rolled %d", player, game.LastRoll)
+		if game.FirstRoll != 0 && game.HasRolled {
+			event = fmt.Sprintf("%s rolled %d, then %d", player, game.FirstRoll, game.LastRoll)
+		}
+	}
+	cg.history = append(cg.history, event)
+	fmt.Fprintln(cg.out(), event)
+	if game.Phase == "robber" && action.Type == ActionRoll && cg.agents[player.ID] == nil {
+		fmt.Fprintln(cg.out(), "A seven! Everyone with more than 7 cards discarded half. Move the robber with: robber <tile> [player]")
+	}
+}
+
+// Turns a command's arguments into the action it asks for
+func parseAction(game *CatanGame, name string, args []string) (Action, error) {
+	player := CurrentPlayer(game)
+	action := Action{PlayerID: player.ID}
+	numbers := func(count int) ([]int, error) {
+		if len(args) < count {
+			return nil, fmt.Errorf("%d numbers are needed", count)
+		}
+		values := make([]int, count)
+		for i := range values {
+			n, err := strconv.Atoi(args[i])
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", args[i])
+			}
+			values[i] = n
+		}
+		return values, nil
+	}
+	// Roads and ships are listed lowest vertex first
+	edge := func(a, b int) (int, int) { return min(a, b), max(a, b) }
+
+	switch name {
+	case "roll":
+		action.Type = ActionRoll
+	case "end":
+		action.Type = ActionEndTurn
+	case "buy dev":
+		action.Type = ActionBuyDevCard
+	case "play knight":
+		action.Type = ActionPlayKnight
+	case "play roads":
+		action.Type = ActionPlayRoadBuilding
+	case "force trade", "give back":
+		action.Type = ActionForcedTrade
+		if name == "give back" {
+			if len(args) != 1 {
+				return action, errors.New("say which resource to give back")
+			}
+			resource, ok := resourceNames[args[0]]
+			if !ok {
+				return action, fmt.Errorf("%q is not a resource", args[0])
+			}
+			action.Type, action.Give = ActionGiveBack, resource
+		}
+		if opponent := tokenOpponent(game, player); opponent != nil {
+			action.VictimID = opponent.ID
+		}
+	case "return robber":
+		action.Type = ActionReturnRobber
+		if desert := desertTile(game); desert != nil {
+			action.TileID = desert.ID
+		}
+
+	case "build road", "build ship":
+		v, err := numbers(2)
+		if err != nil {
+			return action, err
+		}
+		action.Type = ActionBuildRoad
+		if name == "build ship" {
+			action.Type = ActionBuildShip
+		}
+		action.VertexID, action.VertexID2 = edge(v[0], v[1])
+
+	case "build settlement", "city":
+		v, err := numbers(1)
+		if err != nil {
+			return action, err
+		}
+		action.Type = ActionBuildSettlement
+		if name == "city" {
+			action.Type = ActionBuildCity
+		}
+		action.VertexID = v[0]
+
+	case "move ship":
+		if len(args) == 5 && args[2] == "to" {
+			args = append(args[:2], args[3:]...)
+		}
+		v, err := numbers(4)
+		if err != nil {
+			return action, err
+		}
+		action.Type = ActionMoveShip
+		from0, from1 := edge(v[0], v[1])
+		action.MoveFrom = [2]int{from0, from1}
+		action.VertexID, action.VertexID2 = edge(v[2], v[3])
+
+	case "play plenty", "play monopoly":
+		count := 2
+		action.Type = ActionPlayYearOfPlenty
+		if name == "play monopoly" {
+			count, action.Type = 1, ActionPlayMonopoly
+		}
+		if len(args) != count {
+			return action, fmt.Errorf("%d resources are needed", count)
+		}
+		var resources []string
+		for _, arg := range args {
+			resource, ok := resourceNames[arg]
+			if !ok {
+				return action, fmt.Errorf("%q is not a resource", arg)
+			}
+			resources = append(resources, resource)
+		}
+		if count == 1 {
+			action.Get = resources[0]
+		} else {
+			// Listed in the usual resource order, as LegalActions has them
+			sort.Slice(resources, func(i, j int) bool { return resourceIndex(resources[i]) < resourceIndex(resources[j]) })
+			action.Give, action.Get = resources[0], resources[1]
+		}
+
+	case "robber":
+		v, err := numbers(1)
+		if err != nil {
+			return action, err
+		}
+		action.Type = ActionMoveRobber
+		action.TileID = TileID(v[0])
+		if len(args) > 1 {
+			victim, err := strconv.Atoi(args[1])
+			if err != nil {
+				return action, fmt.Errorf("%q is not a player", args[1])
+			}
+			action.VictimID = victim
+		} else if tile := GetTileByID(game, action.TileID); tile != nil {
+			victims := robberVictims(game, player, tile.ID)
+			if len(victims) > 1 {
+				return action, fmt.Errorf("choose who to steal from by number: %s", playerLabels(game, victims))
+			}
+			if len(victims) == 1 {
+				action.VictimID = victims[0]
+			}
+		}
+
+	case "neutral road", "neutral settlement":
+		count := 2
+		action.Type = ActionNeutralRoad
+		if name == "neutral settlement" {
+			count, action.Type = 1, ActionNeutralSettlement
+		}
+		v, err := numbers(count)
+		if err != nil {
+			return action, err
+		}
+		action.VertexID = v[0]
+		if count == 2 {
+			action.VertexID, action.VertexID2 = edge(v[0], v[1])
+		}
+		if len(args) == count+2 && args[count] == "for" {
+			neutral, err := strconv.Atoi(args[count+1])
+			if err != nil {
+				return action, fmt.Errorf("%q is not a player", args[count+1])
+			}
+			action.NeutralID = neutral
+		} else {
+			// The first neutral player the piece can go to
+			for _, legal := range LegalActions(game) {
+				if legal.Type == action.Type && legal.VertexID == action.VertexID && legal.VertexID2 == action.VertexID2 {
+					action.NeutralID = legal.NeutralID
+					break
+				}
+			}
+		}
+
+	case "trade bank":
+		if len(args) == 3 {
+			amount, err := strconv.Atoi(args[0])
+			if err != nil {
+				return action, fmt.Errorf("%q is not a number", args[0])
+			}
+			if give, ok := resourceNames[args[1]]; ok && amount != TradeRatio(game, player, give) {
+				return action, fmt.Errorf("you trade %s with the bank at %d:1, not %d:1", give, TradeRatio(game, player, give), amount)
+			}
+			args = args[1:]
+		}
+		if len(args) != 2 {
+			return action, errors.New("say what to give and what to get")
+		}
+		give, ok := resourceNames[args[0]]
+		if !ok {
+			return action, fmt.Errorf("%q is not a resource", args[0])
+		}
+		get, ok := resourceNames[args[1]]
+		if !ok {
+			return action, fmt.Errorf("%q is not a resource", args[1])
+		}
+		action.Type, action.Give, action.Get = ActionBankTrade, give, get
+	}
+	return action, nil
+}
+
+func resourceIndex(resource string) int {
+	for i, r := range ResourceTypes {
+		if r == resource {
+			return i
+		}
+	}
+	return len(ResourceTypes)
+}
+
+// Says why a move the engine refused is not allowed
+func explainIllegal(game *CatanGame, action Action) error {
+	player := CurrentPlayer(game)
+	graph := game.Board.Graph
+	if err := explainPhase(game, action.Type); err != nil {
+		return err
+	}
+
+	edgeProblem := func(piece string) error {
+		edge := graph.EdgeBetween(action.VertexID, action.VertexID2)
+		if edge < 0 {
+			return fmt.Errorf("vertices %d and %d are not joined by an edge", action.VertexID, action.VertexID2)
+		}
+		if !RoadEmptySpace(action.VertexID, action.VertexID2, game) {
+			return fmt.Errorf("edge %s already has a road or ship on it", EdgeKey(action.VertexID, action.VertexID2))
+		}
+		if piece == "road" && !landEdge(game.Board, edge) {
+			return fmt.Errorf("edge %s is out at sea, build a ship there instead", EdgeKey(action.VertexID, action.VertexID2))
+		}
+		if piece == "ship" && !seaEdge(game.Board, edge) {
+			return fmt.Errorf("edge %s is not next to the sea", EdgeKey(action.VertexID, action.VertexID2))
+		}
+		return fmt.Errorf("a %s has to join one of your %ss or buildings", piece, piece)
+	}
+
+	switch action.Type {
+	case ActionBuildRoad:
+		if game.FreeRoads == 0 && !CanPlayerAfford(player, "road") {
+			return cannotAfford("road")
+		}
+		if countRoads(game, player) >= MaxRoads {
+			return fmt.Errorf("you have built all %d of your roads", MaxRoads)
+		}
+		return edgeProblem("road")
+	case ActionBuildShip:
+		if !HasSea(game.Board) {
+			return errors.New("there is no sea on this board")
+		}
+		if !CanPlayerAfford(player, "ship") {
+			return cannotAfford("ship")
+		}
+		return edgeProblem("ship")
+	case ActionMoveShip:
+		if game.ShipMoved {
+			return errors.New("you have already moved a ship this turn")
+		}
+		if ShipOwner(game, action.MoveFrom[0], action.MoveFrom[1]) != player {
+			return fmt.Errorf("you have no ship on %s", EdgeKey(action.MoveFrom[0], action.MoveFrom[1]))
+		}
+		return errors.New("only a ship at the open end of a line, not built this turn, can move, and only to where a ship could be built")
+	case ActionBuildSettlement:
+		if !CanPlayerAfford(player, "settlement") {
+			return cannotAfford("settlement")
+		}
+		if countBuildings(game, player, 1) >= MaxSettlements {
+			return fmt.Errorf("you have built all %d of your settlements, turn one into a city", MaxSettlements)
+		}
+		if action.VertexID < 1 || action.VertexID > graph.VertexCount() {
+			return fmt.Errorf("there is no vertex %d", action.VertexID)
+		}
+		if !containsVertex(ComputeValidVertexPlacements(game), action.VertexID) {
+			return fmt.Errorf("vertex %d is taken or next to another building", action.VertexID)
+		}
+		return fmt.Errorf("vertex %d is not on one of your roads or ships", action.VertexID)
+	case ActionBuildCity:
+		if !CanPlayerAfford(player, "city") {
+			return cannotAfford("city")
+		}
+		if countBuildings(game, player, 2) >= MaxCities {
+			return fmt.Errorf("you have built all %d of your cities", MaxCities)
+		}
+		return fmt.Errorf("you have no settlement on vertex %d", action.VertexID)
+	case ActionBuyDevCard:
+		if !CanPlayerAfford(player, "dev") {
+			return cannotAfford("dev")
+		}
+		return errors.New("there are no development cards left")
+	case ActionPlayKnight, ActionPlayRoadBuilding, ActionPlayYearOfPlenty, ActionPlayMonopoly:
+		card := map[ActionType]string{
+			ActionPlayKnight:       "Knight",
+			ActionPlayRoadBuilding: "Road Building",
+			ActionPlayYearOfPlenty: "Year of Plenty",
+			ActionPlayMonopoly:     "Monopoly",
+		}[action.Type]
+		switch {
+		case game.DevCardPlayed:
+			return errors.New("you have already played a development card this turn")
+		case player.DevelopmentCards[card] == 0:
+			return fmt.Errorf("you have no %s card", card)
+		case player.DevelopmentCards[card] <= player.BoughtThisTurn[card]:
+			return fmt.Errorf("a %s card cannot be played the turn it is bought", card)
+		case action.Type == ActionPlayYearOfPlenty:
+			return errors.New("the bank does not have those cards")
+		}
+		return errors.New("there is nowhere to build a road")
+	case ActionMoveRobber:
+		tile := GetTileByID(game, action.TileID)
+		switch {
+		case tile == nil:
+			return fmt.Errorf("there is no tile %d", action.TileID)
+		case tile.ID == game.Board.RobberPosition:
+			return errors.New("the robber has to move to a different tile")
+		case tile.Resource == Sea:
+			return errors.New("the robber stays on land")
+		}
+		victims := robberVictims(game, player, tile.ID)
+		if len(victims) == 0 {
+			return fmt.Errorf("there is nobody with cards to steal from on tile %d", tile.ID)
+		}
+		return fmt.Errorf("you can steal from %s on tile %d", playerLabels(game, victims), tile.ID)
+	case ActionBankTrade:
+		if action.Give == action.Get {
+			return errors.New("give and get different resources")
+		}
+		if ratio := TradeRatio(game, player, action.Give); player.Resources[action.Give] < ratio {
+			return fmt.Errorf("you need %d %s to trade with the bank", ratio, action.Give)
+		}
+		return fmt.Errorf("the bank has no %s left", action.Get)
+	case ActionForcedTrade, ActionReturnRobber:
+		opponent := tokenOpponent(game, player)
+		switch {
+		case opponent == nil:
+			return errors.New("trade tokens are only used in the 2-player game")
+		case player.TradeTokens < tradeTokenCost(game, player):
+			return fmt.Errorf("that costs %d trade tokens and you have %d", tradeTokenCost(game, player), player.TradeTokens)
+		case action.Type == ActionReturnRobber:
+			return errors.New("the robber is already in the desert")
+		case game.ForcedTrade:
+			return errors.New("you have already forced a trade this turn")
+		}
+		return fmt.Errorf("%s has no cards to trade", opponent)
+	case ActionGiveBack:
+		return fmt.Errorf("you have no %s", action.Give)
+	case ActionNeutralRoad, ActionNeutralSettlement:
+		if game.Phase != "neutral" {
+			return errors.New("nothing is owed to a neutral player")
+		}
+		return fmt.Errorf("a neutral player cannot build there, it has to be connected to one of theirs")
+	}
+	return errors.New("that is not allowed right now")
+}
+
+// Says why this part of the turn does not allow actions of a type at all,
+// nil if it could
+func explainPhase(game *CatanGame, actionType ActionType) error {
+	switch {
+	case game.Phase == "robber" && actionType != ActionMoveRobber:
+		return errors.New("move the robber first: robber <tile> [player]")
+	case game.Phase == "neutral" && actionType != ActionNeutralRoad && actionType != ActionNeutralSettlement:
+		return fmt.Errorf("first build the %s owed to a neutral player", game.NeutralBuild)
+	case game.Phase == "forced" && actionType != ActionGiveBack:
+		return fmt.Errorf("first give back %d cards: give back <resource>", game.GiveBack)
+	case game.Phase != "forced" && actionType == ActionGiveBack:
+		return errors.New("cards are only given back after a forced trade")
+	case game.Phase == "special" && actionType != ActionEndTurn && actionType != ActionBuildRoad && actionType != ActionBuildShip &&
+		actionType != ActionBuildSettlement && actionType != ActionBuildCity && actionType != ActionBuyDevCard:
+		return errors.New("only building and buying are allowed in the special building phase")
+	case actionType == ActionRoll && game.HasRolled:
+		return errors.New("you have already rolled this turn")
+	case game.Phase == "main" && !game.HasRolled && actionType != ActionPlayKnight:
+		return errors.New("roll the dice first")
+	}
+	return nil
+}
+
+func cannotAfford(item string) error {
+	var parts []string
+	for _, resource := range ResourceTypes {
+		if amount := BuildCosts[item][resource]; amount > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", amount, resource))
+		}
+	}
+	if item == "dev" {
+		item = "development card"
+	}
+	return fmt.Errorf("you cannot afford a %s, it costs %s", item, strings.Join(parts, ", "))
+}
+
+// offer 2 W 1 B for 1 O [to 3] asks the named player, or each other player
+// in turn, whether they take the trade
+func (cg *CLIGame) offerTrade(game *CatanGame, args []string) error {
+	give, get, to, err := parseOffer(args)
+	if err != nil {
+		return fmt.Errorf("%v, type it as: offer 2 W for 1 O [to 3]", err)
+	}
+	player := CurrentPlayer(game)
+	partners := game.Players
+	if to != 0 {
+		partner := GetPlayerByID(game, to)
+		if partner == nil || partner == player {
+			return fmt.Errorf("there is no other player %d", to)
+		}
+		partners = []*Player{partner}
+	}
+
+	// Check the offer can be paid before asking anyone
+	for resource, amount := range give {
+		if player.Resources[resource] < amount {
+			return fmt.Errorf("you do not have %d %s", amount, resource)
+		}
+	}
+	for _, partner := range partners {
+		if partner == player {
+			continue
+		}
+		question := fmt.Sprintf("%s, will you give %s for %s? (y/n) ", partner, describeCards(get), describeCards(give))
+		var answer string
+		if remote, ok := cg.agents[partner.ID].(*remoteSeat); ok {
+			answer = remote.answer(question)
+			fmt.Fprintln(cg.out(), question+answer)
+		} else if cg.agents[partner.ID] != nil {
+			answer = "no"
+			if botAccepts(partner, give, get) {
+				answer = "yes"
+			}
+			fmt.Fprintln(cg.out(), question+answer)
+		} else if answer, err = cg.readLine(question); err != nil {
+			return err
+		}
+		if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+			continue
+		}
+		if err := TradeWithPlayer(game, partner, give, get); err != nil {
+			fmt.Fprintln(cg.out(), err)
+			continue
+		}
+		event := fmt.Sprintf("%s traded %s to %s for %s", player, describeCards(give), partner, describeCards(get))
+		cg.history = append(cg.history, event)
+		fmt.Fprintln(cg.out(), event)
+		return nil
+	}
+	return errors.New("nobody took the offer")
+}
+
+// Reads "<n> <resource> ... for <n> <resource> ... [to <player>]"
+func parseOffer(args []string) (map[string]int, map[string]int, int, error) {
+	give, get := make(map[string]int), make(map[string]int)
+	side := give
+	onGetSide := false
+	to := 0
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "for" && !onGetSide:
+			side, onGetSide = get, true
+		case args[i] == "to" && i == len(args)-2:
+			player, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("%q is not a player", args[i+1])
+			}
+			to = player
+			i++
+		case i+1 < len(args):
+			amount, err := strconv.Atoi(args[i])
+			if err != nil || amount < 1 {
+				return nil, nil, 0, fmt.Errorf("%q is not a number of cards", args[i])
+			}
+			resource, ok := resourceNames[args[i+1]]
+			if !ok {
+				return nil, nil, 0, fmt.Errorf("%q is not a resource", args[i+1])
+			}
+			side[resource] += amount
+			i++
+		default:
+			return nil, nil, 0, fmt.Errorf("%q is missing its resource", args[i])
+		}
+	}
+	if len(give) == 0 || len(get) == 0 {
+		return nil, nil, 0, errors.New("say what you give and what you want")
+	}
+	return give, get, to, nil
+}
+
+// Cards like "2 W, 1 O", in the usual resource order
+func describeCards(cards map[string]int) string {
+	var parts []string
+	for _, resource := range ResourceTypes {
+		if cards[resource] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", cards[resource], resource))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printHand(w io.Writer, player *Player) {
+	fmt.Fprintf(w, "%s has %s\n", player, describeCards(player.Resources))
+	var cards []string
+	for _, card := range DevCardTypes {
+		if count := player.DevelopmentCards[card]; count > 0 {
+			cards = append(cards, fmt.Sprintf("%d %s", count, card))
+		}
+	}
+	if len(cards) > 0 {
+		fmt.Fprintf(w, "Development cards: %s\n", strings.Join(cards, ", "))
+	}
+}
+
+// Everyone's points and how many cards they hold, and which cards too if
+// hands are open
+func printScores(w io.Writer, game *CatanGame, openHands bool) {
+	for _, player := range game.Players {
+		cards, extra := "", ""
+		if openHands && handSize(player) > 0 {
+			cards = " (" + describeCards(player.Resources) + ")"
+		}
+		if player.ID == game.LongestRoadID {
+			extra += ", longest road"
+		}
+		if player.ID == game.LargestArmyID {
+			extra += ", largest army"
+		}
+		if len(game.Neutrals) > 0 {
+			extra += fmt.Sprintf(", %d trade tokens", player.TradeTokens)
+		}
+		fmt.Fprintf(w, "%s: %d points, %d cards%s, %d knights played%s\n",
+			playerLabel(player), player.VictoryPoints, handSize(player), cards, player.KnightsPlayed, extra)
+	}
+}
+
+func printHelp(w io.Writer) {
+	for _, command := range cliCommands {
+		usage := command.names[0]
+		if command.args != "" {
+			usage += " " + command.args
+		}
+		short := ""
+		if len(command.names) > 1 {
+			short = " (" + strings.Join(command.names[1:], ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %-50s %s%s\n", usage, command.help, short)
+	}
+	fmt.Fprintln(w, "Resources can be written B, L, S, W, O or brick, lumber, sheep, wheat, ore.")
+}
@@ -0,0 +1,164 @@
+package gameplay
+
+import (
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchCommand(t *testing.T) {
+	tests := []struct {
+		line, command, args string
+	}{
+		{"build road 10 11", "build road", "10 11"},
+		{"br 10 11", "build road", "10 11"},
+		{"build settlement 21", "build settlement", "21"},
+		{"city 21", "city", "21"},
+		{"build city 21", "city", "21"},
+		{"buy dev", "buy dev", ""},
+		{"trade bank 4 w o", "trade bank", "4 w o"},
+		{"trade w o", "trade bank", "w o"},
+		{"play knight", "play knight", ""},
+		{"ms 1 2 to 3 4", "move ship", "1 2 to 3 4"},
+		{"?", "help", ""},
+		{"Build Road 10 11", "build road", "10 11"},
+	}
+	for _, test := range tests {
+		command, args := matchCommand(strings.Fields(test.line))
+		if command == nil || command.names[0] != test.command || strings.Join(args, " ") != test.args {
+			t.Errorf("%q matched %v with %v", test.line, command, args)
+		}
+	}
+	if command, _ := matchCommand([]string{"fly"}); command != nil {
+		t.Errorf("fly matched %v", command.names[0])
+	}
+}
+
+func TestParseOffer(t *testing.T) {
+	give, get, to, err := parseOffer(strings.Fields("2 w 1 brick for 1 o to 3"))
+	if err != nil || give["W"] != 2 || give["B"] != 1 || get["O"] != 1 || to != 3 {
+		t.Errorf("got %v for %v to %d, %v", give, get, to, err)
+	}
+	for _, line := range []string{"2 w", "for 1 o", "2 gold for 1 o", "w for o"} {
+		if _, _, _, err := parseOffer(strings.Fields(line)); err == nil {
+			t.Errorf("%q was accepted", line)
+		}
+	}
+}
+
+func setUpGame(t *testing.T) *CatanGame {
+	t.Helper()
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	BeginSetup(game, game.Players[0])
+	for game.Phase == "setup" {
+		if err := ApplyAction(game, LegalActions(game)[0], nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game
+}
+
+func TestExplainIllegal(t *testing.T) {
+	game := setUpGame(t)
+	player := CurrentPlayer(game)
+	tests := []struct {
+		words    string
+		expected string
+	}{
+		{"build settlement 1", "roll the dice first"},
+		{"play knight", "no Knight card"},
+	}
+	for _, test := range tests {
+		command, args := matchCommand(strings.Fields(test.words))
+		action, err := parseAction(game, command.names[0], args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := explainIllegal(game, action); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%q was explained as %v", test.words, err)
+		}
+	}
+
+	game.HasRolled = true
+	for resource := range player.Resources {
+		player.Resources[resource] = 0
+	}
+	action := Action{Type: ActionBuildCity, PlayerID: player.ID, VertexID: 1}
+	if err := explainIllegal(game, action); err == nil || !strings.Contains(err.Error(), "2 W, 3 O") {
+		t.Errorf("a city with no cards was explained as %v", err)
+	}
+}
+
+// A scripted turn: a mistake, a roll, a look at the hand and the end of the
+// turn, then input runs out and the game is saved
+func TestPlay(t *testing.T) {
+	game := setUpGame(t)
+	cg := &CLIGame{
+		Input:    strings.NewReader("build road 1 2\nfly\nr\nhand\nhistory\nend\n"),
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(2)),
+	}
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if game.Phase == "main" && CurrentPlayer(game).ID != 2 {
+		t.Errorf("Player %d is to play after the first turn ended", CurrentPlayer(game).ID)
+	}
+	if len(cg.history) != 2 || !strings.HasPrefix(cg.history[0], "Player 1 rolled") {
+		t.Errorf("got history %q", cg.history)
+	}
+	if _, err := LoadGame(cg.SavePath); err != nil {
+		t.Error(err)
+	}
+}
+
+// A building command without a place offers a menu, and back cancels it
+func TestPlacementMenu(t *testing.T) {
+	game := setUpGame(t)
+	game.HasRolled = true
+	player := CurrentPlayer(game)
+	player.Resources["B"], player.Resources["L"] = 1, 1
+	var roads []Action
+	for _, action := range LegalActions(game) {
+		if action.Type == ActionBuildRoad {
+			roads = append(roads, action)
+		}
+	}
+
+	cg := &CLIGame{Input: strings.NewReader("br\nback\nbs\nbr\n2\n"), SavePath: filepath.Join(t.TempDir(), "save.json")}
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if RoadOwner(game, roads[1].VertexID, roads[1].VertexID2) != player || countRoads(game, player) != 3 {
+		t.Errorf("the second menu choice, %s, was not built alone", roads[1])
+	}
+	if len(cg.history) != 1 {
+		t.Errorf("got history %q", cg.history)
+	}
+}
+
+// Commands and answers are read in any case, but file names keep theirs
+func TestCommandCase(t *testing.T) {
+	game := setUpGame(t)
+	game.Players[0].Resources["W"]++
+	game.Players[1].Resources["S"]++
+	path := filepath.Join(t.TempDir(), "MyGame.json")
+	var output strings.Builder
+	cg := &CLIGame{
+		Input:    strings.NewReader("R\nOffer 1 W for 1 S to 2\nYES\nSAVE " + path + "\n"),
+		Output:   &output,
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(2)),
+	}
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if !strings.Contains(output.String(), "Player 1 traded 1 W to Player 2 for 1 S") {
+		t.Errorf("the offer was not taken:\n%s", output.String())
+	}
+	if _, err := LoadGame(path); err != nil {
+		t.Errorf("the game was not saved as %s: %v", path, err)
+	}
+}
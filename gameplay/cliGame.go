@@ -6,103 +6,176 @@ import (
 	"catango/helpers"
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
+	"math/rand"
 )
 
 type CLIGame struct {
 	BaseGame
-	Input io.Reader // Injected input source
+	Input    io.Reader  // Injected input source, only ever read through in()
+	Output   io.Writer  // Where the game's messages go, standard output if nil
+	SavePath string     // Where to save if input runs out, DefaultSavePath if empty
+	Rand     *rand.Rand // Dice and card draws during Play, seeded from the clock if nil
+	// FullScreen draws the game on the terminal's alternate screen from the
+	// start of setup, see tui.go. Close puts the terminal back.
+	FullScreen bool
+	// HotSeat keeps hands private when players share the terminal, clearing
+	// it and waiting for the next player before showing their cards, see
+	// hotSeat.go. Without it hands are open, as in teaching games.
+	HotSeat bool
+	// Seats names the players and says which are bots, see SetSeats. With
+	// none, everyone plays from this terminal under their number.
+	Seats []Seat
+	// Join waits for the player of remote seat number (from 1) to connect and
+	// gives their connection, see remote.go. Without it remote seats cannot be
+	// played.
+	Join func(number int, seat Seat) (io.ReadWriter, error)
+
+	agents  map[int]Agent // Bots and remote seats by player ID
+	reader  *bufio.Reader
+	history []string // What happened each move, for the history command
+	screen  *screen
+	shown   int // ID of the player whose cards are showing in hot-seat mode
 }
 
-func (cg *CLIGame) Initialize() int {
-	reader := bufio.NewReader(cg.Input)
-
-	for {
-		fmt.Println("Welcome to Catan!")
-		fmt.Print("Please enter the number of players (3 or 4): ")
-
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Println("Error reading input. Please try again.")
-			continue
+// Initialize asks for the number of players, failing only if input runs out
+func (cg *CLIGame) Initialize() (int, error) {
+	fmt.Fprintln(cg.out(), "Welcome to Catan!")
+	return cg.readInt("Please enter the number of players (2 to 6, 5 or 6 use the extension board): ", func(n int) error {
+		if n < BaseBoard.MinPlayers || n > ExtensionBoard.MaxPlayers {
+			return fmt.Errorf("%d players cannot play, it takes %d to %d", n, BaseBoard.MinPlayers, ExtensionBoard.MaxPlayers)
 		}
-
-		input = strings.TrimSpace(input)
-		playerNum, err := strconv.Atoi(input)
-		if err != nil || (playerNum != 3 && playerNum != 4) {
-			fmt.Println("Invalid input. Please enter either 3 or 4.")
-			continue
-		}
-
-		return playerNum
-	}
+		return nil
+	})
 }
 
 func (cg *CLIGame) Start(game *CatanGame) {
-	fmt.Println("Game is starting!")
-	fmt.Println("Current Phase:", game.Phase)
+	fmt.Fprintln(cg.out(), "Game is starting!")
+	fmt.Fprintln(cg.out(), "Current Phase:", game.Phase)
 	//PrintGameBoard(game)
 	cg.BaseGame.Start(game) // Call base implementation
-	fmt.Println("Game phase set to:", game.Phase)
+	cg.follow(game)
+	fmt.Fprintln(cg.out(), "Game phase set to:", game.Phase)
+}
+
+// NewGame starts a game for the seats, its board, dice and bots all seeded
+// from seed so the same input plays the same game, and has everyone roll
+// for who goes first
+func (cg *CLIGame) NewGame(seats []Seat, seed int64) (*CatanGame, *Player, error) {
+	cg.Rand = rand.New(rand.NewSource(seed))
+	ids := make([]int, len(seats))
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	game := NewSeededCatanGame(ids, seed)
+	if err := cg.SetSeats(game, seats); err != nil {
+		return nil, nil, err
+	}
+	cg.Start(game)
+
+	playerSelector := &CLIPlayerSelector{}
+	startingPlayer, err := playerSelector.SelectStartingPlayer(game, cg)
+	return game, startingPlayer, err
 }
 
 type CLIPlayerSelector struct {
 	BasePlayerSelector // Embed the base implementation
 }
 
-func (cps *CLIPlayerSelector) SelectStartingPlayer(game *CatanGame, r io.Reader) *Player {
-	reader := bufio.NewReader(r)
-
+// SelectStartingPlayer has everyone roll, reading ENTER presses from the
+// CLI game's input, with the CLI game's Rand if it has one. If input runs
+// out the dice are rolled anyway, so there is still a starting player, and
+// ErrInputClosed is returned with them.
+func (cps *CLIPlayerSelector) SelectStartingPlayer(game *CatanGame, cg *CLIGame) (*Player, error) {
+	var inputErr error
 	rollFunc := func(player *Player) int {
-		fmt.Printf("Player %d, press ENTER to roll the die...", player.ID)
-		reader.ReadString('\n')
+		if inputErr == nil && cg.agents[player.ID] == nil {
+			inputErr = cg.waitForEnter(fmt.Sprintf("%s, press ENTER to roll the die...", player))
+		}
 		roll := helpers.RollDie()
-		fmt.Printf("Player %d rolled a %d\n", player.ID, roll)
+		if cg.Rand != nil {
+			roll = cg.Rand.Intn(6) + 1
+		}
+		fmt.Fprintf(cg.out(), "%s rolled a %d\n", player, roll)
 		return roll
 	}
 
-	fmt.Println("\n=== Starting Player Selection ===")
+	fmt.Fprintln(cg.out(), "\n=== Starting Player Selection ===")
 	winner := cps.BasePlayerSelector.SelectStartingPlayer(game, rollFunc)
-	fmt.Printf("🎉 Player %d will go first!\n", winner.ID)
-	return winner
+	fmt.Fprintf(cg.out(), "🎉 %s will go first!\n", winner)
+	return winner, inputErr
 }
 
-func (cg *CLIGame) SnakeBuild(game *CatanGame, startingPlayer *Player, playerCount int) {
-	fmt.Println("\n=== Starting Build Phase ===")
-	order := GenerateSnakeOrder(game, startingPlayer, playerCount)
-
-	for _, playerID := range order {
-		player := game.Players[playerID]
-		PrintRaw(game)
-		var ValidVertices = ComputeValidVertexPlacements(game)
-		var vertexID1 int
-		var vertexID2 int
-
-		i := 0
-		for i < 1 {
-			vertexID1 = cg.readInt(fmt.Sprintf("Enter the ID of the vertex where Player %d wants to build a settlement: ", player.ID))
-			if helpers.ContainsInt(ValidVertices, vertexID1) {
-				PlaceSettlement(vertexID1, player, game)
-				if player.VictoryPoints == 1 {
-					//if the player has one victory point at this point then they get their resources
-					for _, tileID := range GetVertexByID(game, vertexID1).TileIds {
-						BankToPlayerResource(game, player, GetTileByID(game, tileID).Resource, 1)
-					}
-				}
-				i++
-			}
+// SnakeBuild runs the setup phase through the rules engine (see BeginSetup),
+// asking each player in turn where to place, including the pieces they place
+// for neutral players in a 2-player game. Typing back at the road question
+// takes the settlement back up. If input runs out the game is saved and
+// ErrInputClosed returned.
+func (cg *CLIGame) SnakeBuild(game *CatanGame, startingPlayer *Player) error {
+	BeginSetup(game, startingPlayer)
+	return cg.placeSetup(game)
+}
+
+// Resume carries on a loaded game, from setup if it was saved then, until
+// it is won or stopped as Play is
+func (cg *CLIGame) Resume(game *CatanGame) error {
+	if game.Phase == "setup" {
+		if err := cg.placeSetup(game); err != nil {
+			return err
 		}
+	}
+	return cg.Play(game)
+}
 
-		for i > 0 {
-			vertexID2 = cg.readInt(fmt.Sprintf("Enter the ID of the vertex where Player %d wants to build a road from that settlement: ", player.ID))
-			if helpers.ContainsInt(GetAdjacentVertices(vertexID1, game), vertexID2) {
-				PlaceRoad(vertexID1, vertexID2, player, game)
-				i--
+// Asks for the setup placements still to make
+func (cg *CLIGame) placeSetup(game *CatanGame) error {
+	cg.watch(game)
+	fmt.Fprintln(cg.out(), "\n=== Starting Build Phase ===")
+
+	var beforeSettlement *CatanGame
+	for game.Phase == "setup" {
+		if cg.botMove(game) {
+			continue
+		}
+		if err := cg.handOver(game); err != nil {
+			return cg.stopped(game, err)
+		}
+		player := CurrentPlayer(game)
+		forWhom := ""
+		if owner := setupOwner(game); owner.Neutral {
+			forWhom = fmt.Sprintf(" for neutral %s", owner)
+		}
+
+		var action Action
+		var err error
+		if game.SetupVertex == 0 {
+			beforeSettlement = game.Clone()
+			action, err = cg.chooseAction(game, LegalActions(game), fmt.Sprintf("%s, choose where to build a settlement%s: ", player, forWhom))
+			if err == ErrCancelled {
+				fmt.Fprintln(cg.out(), "There is nothing to go back to, the settlement comes first.")
+				continue
+			}
+		} else {
+			action, err = cg.chooseAction(game, LegalActions(game), fmt.Sprintf("%s, choose where to build a road%s from that settlement, or back to move the settlement: ", player, forWhom))
+			if err == ErrCancelled && beforeSettlement == nil {
+				fmt.Fprintln(cg.out(), "The settlement was placed before the game was saved, it cannot be moved now.")
+				continue
+			}
+			if err == ErrCancelled {
+				*game = *beforeSettlement
+				continue
 			}
 		}
+		if err != nil {
+			return cg.stopped(game, err)
+		}
+		if err := ApplyAction(game, action, nil); err != nil {
+			return err // The question only accepts legal answers
+		}
 	}
 
-	fmt.Println("Snake building phase completed!")
-	PrintGameBoard(game)
+	fmt.Fprintln(cg.out(), "Snake building phase completed!")
+	if cg.screen == nil {
+		fmt.Fprint(cg.out(), RenderBoard(game, true))
+	}
+	return nil
 }
@@ -0,0 +1,153 @@
+// cliInput.go
+
+// Reading what players type, and where what the game says goes. All reads go
+// through one buffered reader over CLIGame.Input, so nothing typed ahead is
+// lost between prompts. Bad answers are explained and asked for again, "back"
+// or "cancel" abandons the current choice, and running out of input ends the
+// game with it saved.
+package gameplay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCancelled is returned when the player types back or cancel
+	ErrCancelled = errors.New("cancelled")
+	// ErrInputClosed is returned once there is nothing more to read
+	ErrInputClosed = errors.New("input closed")
+)
+
+// Where a game is saved when input runs out, if CLIGame.SavePath is empty
+const DefaultSavePath = "catango-save.json"
+
+// Where everything the game says goes: the log panel in full-screen mode,
+// otherwise Output or standard output
+func (cg *CLIGame) out() io.Writer {
+	switch {
+	case cg.screen != nil:
+		return cg.screen
+	case cg.Output != nil:
+		return cg.Output
+	}
+	return os.Stdout
+}
+
+func (cg *CLIGame) in() *bufio.Reader {
+	if cg.reader == nil {
+		cg.reader = bufio.NewReader(cg.Input)
+	}
+	return cg.reader
+}
+
+// readLine shows the prompt and reads one line without its surrounding space
+func (cg *CLIGame) readLine(prompt string) (string, error) {
+	if cg.screen != nil {
+		cg.screen.draw(prompt)
+	} else {
+		fmt.Fprint(cg.out(), prompt)
+	}
+	line, err := cg.in().ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Fprintln(cg.out())
+		if err == io.EOF {
+			return "", ErrInputClosed
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func isCancel(answer string) bool {
+	answer = strings.ToLower(answer)
+	return answer == "back" || answer == "cancel"
+}
+
+// readInt asks until it gets a number check accepts, explaining what was
+// wrong with each other answer. A nil check accepts any number.
+func (cg *CLIGame) readInt(prompt string, check func(int) error) (int, error) {
+	for {
+		answer, err := cg.readLine(prompt)
+		if err != nil {
+			return 0, err
+		}
+		if isCancel(answer) {
+			return 0, ErrCancelled
+		}
+		number, err := strconv.Atoi(answer)
+		if answer == "" {
+			fmt.Fprintln(cg.out(), "Please type a number.")
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(cg.out(), "%q is not a number, please try again.\n", answer)
+			continue
+		}
+		if check != nil {
+			if err := check(number); err != nil {
+				fmt.Fprintf(cg.out(), "%v, please try again.\n", err)
+				continue
+			}
+		}
+		return number, nil
+	}
+}
+
+// chooseAction shows the choices on the board and asks for one by its number
+func (cg *CLIGame) chooseAction(game *CatanGame, choices []Action, prompt string) (Action, error) {
+	if cg.screen != nil {
+		// Shown in place of the board until the choice is made
+		cg.screen.board = RenderChoices(game, choices, true)
+		defer func() { cg.screen.board = "" }()
+	} else {
+		fmt.Fprint(cg.out(), RenderChoices(game, choices, true))
+	}
+	printMenu(cg.out(), game, choices, cg.menuWidth())
+	n, err := cg.readInt(prompt, func(n int) error {
+		if n < 1 || n > len(choices) {
+			return fmt.Errorf("there is no choice %d, choose 1 to %d", n, len(choices))
+		}
+		return nil
+	})
+	if err != nil {
+		return Action{}, err
+	}
+	return choices[n-1], nil
+}
+
+// waitForEnter shows the prompt and waits for a line, whatever is on it
+func (cg *CLIGame) waitForEnter(prompt string) error {
+	_, err := cg.readLine(prompt)
+	return err
+}
+
+// stopped passes on the error that stopped the game, saving the game first
+// if it was input running out
+func (cg *CLIGame) stopped(game *CatanGame, err error) error {
+	if err == ErrInputClosed {
+		if saveErr := cg.SaveUnfinished(game); saveErr != nil {
+			return saveErr
+		}
+	}
+	return err
+}
+
+// SaveUnfinished saves a game the players stopped part way through and says
+// where, so it can be loaded to carry on
+func (cg *CLIGame) SaveUnfinished(game *CatanGame) error {
+	path := cg.SavePath
+	if path == "" {
+		path = DefaultSavePath
+	}
+	if err := SaveSession(game, cg.Seats, path); err != nil {
+		return fmt.Errorf("the game could not be saved: %w", err)
+	}
+	fmt.Fprintf(cg.out(), "Input ended, the game is saved in %s\n", path)
+	return nil
+}
@@ -0,0 +1,63 @@
+package gameplay
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadInt(t *testing.T) {
+	positive := func(n int) error {
+		if n < 1 {
+			return fmt.Errorf("%d is too small", n)
+		}
+		return nil
+	}
+	cg := &CLIGame{Input: strings.NewReader("\nseven\n0\n7\n8")}
+	for _, expected := range []int{7, 8} {
+		if n, err := cg.readInt("? ", positive); n != expected || err != nil {
+			t.Errorf("got %d and %v, expected %d", n, err, expected)
+		}
+	}
+	if _, err := cg.readInt("? ", positive); !errors.Is(err, ErrInputClosed) {
+		t.Errorf("got %v at the end of input", err)
+	}
+
+	cg = &CLIGame{Input: strings.NewReader("Back\n")}
+	if _, err := cg.readInt("? ", nil); !errors.Is(err, ErrCancelled) {
+		t.Errorf("got %v after back", err)
+	}
+}
+
+// Going back moves the settlement, and running out of input saves the game
+func TestSnakeBuildInput(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	plan := game.Clone()
+	BeginSetup(plan, plan.Players[0])
+	legal := LegalActions(plan)
+	first, second := legal[0], legal[len(legal)-1]
+	applyAction(plan, second, nil)
+	road := LegalActions(plan)[0]
+	applyAction(plan, road, nil)
+
+	cg := &CLIGame{
+		Input:    strings.NewReader(fmt.Sprintf("1\nback\n%d\n1\n", len(legal))),
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+	}
+	if err := cg.SnakeBuild(game, game.Players[0]); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if VertexOwner(game, first.VertexID) != nil || !game.Equal(plan) {
+		t.Error("going back did not move the settlement")
+	}
+
+	saved, err := LoadGame(cg.SavePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Equal(game) {
+		t.Error("the saved game differs from the one played")
+	}
+}
@@ -4,6 +4,7 @@
 package gameplay
 
 import (
+	"fmt"
 	"math/rand"
 	"time"
 )
@@ -11,23 +12,45 @@ import (
 type Player struct {
 	ID               int
 	Resources        map[string]int
-	VictoryPoints    int
+	VictoryPoints    int // Public points: buildings plus longest road and largest army
 	DevelopmentCards map[string]int
+	BoughtThisTurn   map[string]int // Cards bought this turn, which cannot be played yet
+	KnightsPlayed    int
 	LongestRoad      int
+	Neutral          bool   // A neutral player of the 2-player variant, see twoPlayer.go
+	Islands          uint64 // Bit i set once the player has settled island i, see seafarers.go
+	Name             string // Shown instead of "Player <ID>" if set, see seats.go
+	Color            string // One of ColorNames, the default for the ID if empty
+	TradeTokens      int    // Tokens of the 2-player variant, see twoPlayer.go
 }
 
+// String is the player's name, or "Player <ID>" if they have none
+func (p *Player) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return fmt.Sprintf("Player %d", p.ID)
+}
+
+// TileID numbers the tiles from 1, in reading order across the board. It is
+// the only way tiles are referred to: Board.Tiles[id-1] is tile id.
+type TileID int
+
 type Tile struct {
-	ID          int
+	ID          TileID
+	Coord       HexCoord
 	Resource    string
 	NumberToken int
 }
 
+// Vertex and Edge are snapshots handed out by GetVertexByID and GetEdge, the
+// graph itself stores ownership compactly (see graph.go)
 type Vertex struct {
 	ID               int
 	OccupiedBy       *Player // nil if empty
 	Building         int
-	AdjacentVertexes [3]int // Adjacent vertices
-	TileIds          [3]int
+	AdjacentVertexes []int    // Adjacent vertices, 2 or 3 of them
+	TileIds          []TileID // Tiles with this vertex as a corner, 1 to 3 of them
 }
 
 type Edge struct {
@@ -36,30 +59,61 @@ type Edge struct {
 	Vertices   [2]*Vertex
 }
 
-type Graph struct {
-	Vertices map[int]*Vertex
-	Edges    map[string]*Edge
-}
-
 type Port struct {
 	GiveResource string
 	VertexIDs    [2]int
+	Tile         TileID // Tile whose side the port is on
+	Facing       int    // Direction from Tile out to sea, an index into hexDirections
 }
 
 type Board struct {
 	Tiles          []*Tile
-	RobberPosition int
+	RobberPosition TileID
 	Ports          []Port
 	Graph          *Graph
+	Topology       *Topology
+	terrain        *terrain // nil unless the board has sea, see seafarers.go
 }
 
 type CatanGame struct {
 	Players   []*Player
-	Board     *Board
+	Neutrals  []*Player // Neutral players in a 2-player game, they never take a turn
+	Board     *Board    `json:"-"` // Saved as a BoardFile, see save.go
 	TurnIndex int
 	Phase     string
 	Bank      *Bank
 	Cli       bool
+
+	// Setup state, see BeginSetup
+	SetupOrder  []int // Player IDs in snake placement order
+	SetupIndex  int   // Position in SetupOrder of the player placing now
+	SetupVertex int   // Settlement waiting for its road, 0 if none
+
+	// Turn state for the main phase
+	HasRolled     bool
+	LastRoll      int
+	DevCardPlayed bool
+	FreeRoads     int // Roads left from a Road Building card
+	TurnCount     int
+
+	// 2-player variant state, see twoPlayer.go
+	FirstRoll    int    // First of the turn's two rolls, 0 until it is made
+	NeutralBuild string // "road" or "settlement" owed to a neutral player
+	GiveBack     int    // Cards still to give back after a forced trade
+	ForcedTrade  bool   // Whether a forced trade has been made this turn
+
+	// Special building phase of the 5-6 player game
+	SpecialBuild   bool // Whether this game has the phase
+	SpecialBuilder int  // Index in Players of whoever is building now
+
+	// Seafarers state, see seafarers.go
+	IslandBonus int   // Points for the first settlement on each new island
+	ShipMoved   bool  // Whether a ship has been moved this turn
+	NewShips    []int // Edges of ships built this turn, which cannot move yet
+
+	LongestRoadID int // Player ID holding longest road, 0 if none
+	LargestArmyID int // Player ID holding largest army, 0 if none
+	WinnerID      int // 0 until someone reaches VictoryPointsToWin
 }
 
 type DevelopmentCard struct {
@@ -72,6 +126,20 @@ type Bank struct {
 }
 
 func NewCatanGame(playerIDs []int) *CatanGame {
+	return NewSeededCatanGame(playerIDs, time.Now().UnixNano())
+}
+
+// NewSeededCatanGame generates the same board and development deck every time
+// for the same seed, on the board for that many players
+func NewSeededCatanGame(playerIDs []int, seed int64) *CatanGame {
+	// The standard boards have no constraints, so generating them cannot fail
+	game, _ := NewCatanGameOnBoard(playerIDs, BoardSpecFor(len(playerIDs)), seed)
+	return game
+}
+
+// NewCatanGameOnBoard fails if no board keeping spec.Constraints was found
+func NewCatanGameOnBoard(playerIDs []int, spec BoardSpec, seed int64) (*CatanGame, error) {
+	rng := rand.New(rand.NewSource(seed))
 	players := make([]*Player, 0)
 	for _, id := range playerIDs {
 		players = append(players, &Player{
@@ -79,196 +147,300 @@ func NewCatanGame(playerIDs []int) *CatanGame {
 			Resources:        make(map[string]int),
 			VictoryPoints:    0,
 			DevelopmentCards: make(map[string]int),
+			BoughtThisTurn:   make(map[string]int),
 		})
 	}
 
-	board := GenerateBoard()
+	board, err := generateBoard(spec, rng)
+	if err != nil {
+		return nil, err
+	}
 
-	return &CatanGame{
-		Players:   players,
-		Board:     board,
-		TurnIndex: 0,
-		Phase:     "setup",
-		Bank:      GenerateBank(),
-		Cli:       false,
+	game := &CatanGame{
+		Players:      players,
+		Board:        board,
+		TurnIndex:    0,
+		Phase:        "setup",
+		Bank:         generateBank(spec, rng),
+		Cli:          false,
+		SpecialBuild: spec.SpecialBuildPhase,
+		IslandBonus:  spec.IslandBonus,
+	}
+	if len(players) == 2 {
+		addNeutrals(game)
 	}
+	return game, nil
 }
 
-func GenerateBoard() *Board {
-	Tokens := []int{2, 3, 3, 4, 4, 5, 5, 6, 6, 8, 8, 9, 9, 10, 10, 11, 11, 12}
-	Resources := []string{"W", "W", "W", "W", "L", "L", "L", "L", "O", "O", "O", "B", "B", "B", "S", "S", "S", "S", "D"}
-	Ports := []string{"A", "A", "A", "A", "B", "O", "S", "W", "L"} // A = 3:1, otherwise 2:1 ports
-	shuffleSlice(Tokens)
-	shuffleSlice(Resources)
-	shuffleSlice(Ports)
+// Clone returns a copy of the game that shares no mutable state with the
+// original, so bots can simulate moves without touching the real game. Tiles,
+// ports and the graph layout never change during a game and are shared.
+func (game *CatanGame) Clone() *CatanGame {
+	copyPlayers := func(from []*Player) []*Player {
+		if from == nil {
+			return nil
+		}
+		players := make([]*Player, len(from))
+		for i, p := range from {
+			copied := *p
+			copied.Resources = copyCounts(p.Resources)
+			copied.DevelopmentCards = copyCounts(p.DevelopmentCards)
+			copied.BoughtThisTurn = copyCounts(p.BoughtThisTurn)
+			players[i] = &copied
+		}
+		return players
+	}
 
-	board := &Board{
-		// Initialize as a slice of 19 tiles (nil initially)
-		Tiles:          make([]*Tile, 19),
-		RobberPosition: 0, //Will set to desert later
-		Ports:          make([]Port, 0),
+	board := *game.Board
+	board.Graph = game.Board.Graph.Clone()
+
+	clone := *game
+	clone.Players = copyPlayers(game.Players)
+	clone.Neutrals = copyPlayers(game.Neutrals)
+	clone.Board = &board
+	clone.Bank = &Bank{
+		Resources:        copyCounts(game.Bank.Resources),
+		DevelopmentCards: append([]DevelopmentCard(nil), game.Bank.DevelopmentCards...),
 	}
+	clone.SetupOrder = append([]int(nil), game.SetupOrder...)
+	clone.NewShips = append([]int(nil), game.NewShips...)
+	return &clone
+}
 
-	tokenIndex := 0
-	for i := 0; i < 19; i++ {
-		tile := &Tile{
-			ID:       i,
-			Resource: Resources[i],
+// Equal reports whether two games are in the same state
+func (game *CatanGame) Equal(other *CatanGame) bool {
+	if len(game.Players) != len(other.Players) || len(game.Neutrals) != len(other.Neutrals) ||
+		!game.Board.Graph.Equal(other.Board.Graph) {
+		return false
+	}
+	for i, p := range game.Players {
+		if !equalPlayers(p, other.Players[i]) {
+			return false
+		}
+	}
+	for i, p := range game.Neutrals {
+		if !equalPlayers(p, other.Neutrals[i]) {
+			return false
+		}
+	}
+	if len(game.Board.Tiles) != len(other.Board.Tiles) || game.Board.RobberPosition != other.Board.RobberPosition {
+		return false
+	}
+	for i, t := range game.Board.Tiles {
+		if *t != *other.Board.Tiles[i] {
+			return false
 		}
+	}
+	if !equalCounts(game.Bank.Resources, other.Bank.Resources) || len(game.Bank.DevelopmentCards) != len(other.Bank.DevelopmentCards) {
+		return false
+	}
+	for i, card := range game.Bank.DevelopmentCards {
+		if card != other.Bank.DevelopmentCards[i] {
+			return false
+		}
+	}
+	if len(game.SetupOrder) != len(other.SetupOrder) || len(game.NewShips) != len(other.NewShips) {
+		return false
+	}
+	for i, id := range game.SetupOrder {
+		if id != other.SetupOrder[i] {
+			return false
+		}
+	}
+	for i, edge := range game.NewShips {
+		if edge != other.NewShips[i] {
+			return false
+		}
+	}
+	return game.TurnIndex == other.TurnIndex && game.Phase == other.Phase &&
+		game.SetupIndex == other.SetupIndex && game.SetupVertex == other.SetupVertex &&
+		game.HasRolled == other.HasRolled && game.LastRoll == other.LastRoll &&
+		game.DevCardPlayed == other.DevCardPlayed && game.FreeRoads == other.FreeRoads &&
+		game.TurnCount == other.TurnCount && game.FirstRoll == other.FirstRoll &&
+		game.NeutralBuild == other.NeutralBuild && game.GiveBack == other.GiveBack && game.ForcedTrade == other.ForcedTrade &&
+		game.SpecialBuild == other.SpecialBuild &&
+		game.SpecialBuilder == other.SpecialBuilder && game.LongestRoadID == other.LongestRoadID &&
+		game.LargestArmyID == other.LargestArmyID && game.WinnerID == other.WinnerID &&
+		game.IslandBonus == other.IslandBonus && game.ShipMoved == other.ShipMoved
+}
 
-		if Resources[i] != "D" {
-			tile.NumberToken = Tokens[tokenIndex]
-			tokenIndex++
-		} else {
-			tile.NumberToken = 0     // Desert has no number
-			board.RobberPosition = i // Robber starts on desert
+func equalPlayers(p, o *Player) bool {
+	return p.ID == o.ID && p.Name == o.Name && p.Color == o.Color && p.Neutral == o.Neutral && p.VictoryPoints == o.VictoryPoints &&
+		p.KnightsPlayed == o.KnightsPlayed && p.LongestRoad == o.LongestRoad && p.Islands == o.Islands && p.TradeTokens == o.TradeTokens &&
+		equalCounts(p.Resources, o.Resources) && equalCounts(p.DevelopmentCards, o.DevelopmentCards) &&
+		equalCounts(p.BoughtThisTurn, o.BoughtThisTurn)
+}
+
+// Counts are equal if every key has the same count, treating missing keys as 0
+func equalCounts(a, b map[string]int) bool {
+	for k, v := range a {
+		if b[k] != v {
+			return false
 		}
+	}
+	for k, v := range b {
+		if a[k] != v {
+			return false
+		}
+	}
+	return true
+}
 
-		board.Tiles[i] = tile
+func copyCounts(m map[string]int) map[string]int {
+	copied := make(map[string]int, len(m))
+	for k, v := range m {
+		copied[k] = v
 	}
+	return copied
+}
 
-	// Ports placement currently as q,r side
-	//Needs to be redone as port vertex id's
-	portPositions := []struct {
-		VertexIDs [2]int
-	}{
-		{[2]int{1, 2}},
-		{[2]int{4, 5}},
-		{[2]int{15, 16}},
-		{[2]int{27, 38}},
-		{[2]int{46, 47}},
-		{[2]int{51, 52}},
-		{[2]int{48, 49}},
-		{[2]int{29, 39}},
-		{[2]int{8, 18}},
+func GenerateBoard() *Board {
+	board, _ := generateBoard(BaseBoard, rand.New(rand.NewSource(time.Now().UnixNano())))
+	return board
+}
+
+// generateBoard shuffles the spec's tiles and numbers until the board keeps
+// spec.Constraints, then places the ports. Sea tiles stay where the spec puts
+// them.
+func generateBoard(spec BoardSpec, rng *rand.Rand) (*Board, error) {
+	// Copy so shuffling never touches the shared spec
+	Tokens := append([]int(nil), spec.Tokens...)
+	Resources := append([]string(nil), spec.Resources...)
+	Ports := append([]string(nil), spec.Ports...) // A = 3:1, otherwise 2:1 ports
+
+	topology, graph := GenerateTopology(spec.Shape)
+	board := &Board{
+		Ports:    make([]Port, 0),
+		Graph:    graph,
+		Topology: topology,
 	}
+	sea := make(map[HexCoord]bool, len(spec.SeaTiles))
+	for _, coord := range spec.SeaTiles {
+		sea[coord] = true
+	}
+
+	var err error
+	for attempt := 0; attempt < maxBoardAttempts; attempt++ {
+		switch spec.Layout {
+		case LayoutFixed:
+			placeTiles(board, Resources, Tokens, sea)
+		case LayoutSpiral:
+			if len(spec.SpiralTokens) == 0 {
+				return nil, fmt.Errorf("the %s board has no spiral token order", spec.Name)
+			}
+			shuffleSlice(Resources, rng)
+			placeTiles(board, Resources, Tokens, sea)
+			corners := ShapeCorners(spec.Shape)
+			placeSpiralTokens(board, spec.SpiralTokens, corners[rng.Intn(len(corners))])
+		default:
+			shuffleSlice(Tokens, rng)
+			shuffleSlice(Resources, rng)
+			placeTiles(board, Resources, Tokens, sea)
+			spec.Constraints.repairNumbers(board, rng)
+		}
+		if err = spec.Constraints.Check(board); err == nil || spec.Layout == LayoutFixed {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no %s board found in %d tries, last one failed because %v", spec.Name, maxBoardAttempts, err)
+	}
+	if spec.Layout != LayoutFixed {
+		shuffleSlice(Ports, rng)
+	}
+	indexTerrain(board)
 
-	board.Graph = GenerateGraphFromHardcodedData()
+	portPositions := spec.PortPositions
+	if portPositions == nil {
+		portPositions = evenPortPositions(board, len(Ports))
+	}
 
 	for i, pos := range portPositions {
-		port := Port{
-			GiveResource: Ports[i],
-			VertexIDs:    pos.VertexIDs,
+		if err := addPort(board, Ports[i], pos); err != nil {
+			return nil, fmt.Errorf("%s board port %d: %v", spec.Name, i+1, err)
 		}
-		board.Ports = append(board.Ports, port)
 	}
 
-	return board
+	return board, nil
 }
 
-func GenerateGraphFromHardcodedData() *Graph {
-
-	// Hardcoded graph data for the Catan board according to image
-	vertices := map[int]*Vertex{
-		1:  {ID: 1, AdjacentVertexes: [3]int{9, 2}, TileIds: [3]int{1}},
-		2:  {ID: 2, AdjacentVertexes: [3]int{1, 3}, TileIds: [3]int{1}},
-		3:  {ID: 3, AdjacentVertexes: [3]int{2, 4, 11}, TileIds: [3]int{1, 2}},
-		4:  {ID: 4, AdjacentVertexes: [3]int{3, 5}, TileIds: [3]int{2}},
-		5:  {ID: 5, AdjacentVertexes: [3]int{4, 6, 13}, TileIds: [3]int{2, 3}},
-		6:  {ID: 6, AdjacentVertexes: [3]int{5, 7}, TileIds: [3]int{3}},
-		7:  {ID: 7, AdjacentVertexes: [3]int{6, 15}, TileIds: [3]int{3}},
-		8:  {ID: 8, AdjacentVertexes: [3]int{9, 18}, TileIds: [3]int{4}},
-		9:  {ID: 9, AdjacentVertexes: [3]int{1, 8, 10}, TileIds: [3]int{1, 4}},
-		10: {ID: 10, AdjacentVertexes: [3]int{9, 11, 20}, TileIds: [3]int{1, 4, 5}},
-		11: {ID: 11, AdjacentVertexes: [3]int{3, 10, 12}, TileIds: [3]int{1, 2, 5}},
-		12: {ID: 12, AdjacentVertexes: [3]int{11, 13, 22}, TileIds: [3]int{2, 5, 6}},
-		13: {ID: 13, AdjacentVertexes: [3]int{5, 12, 14}, TileIds: [3]int{2, 3, 6}},
-		14: {ID: 14, AdjacentVertexes: [3]int{13, 15, 24}, TileIds: [3]int{3, 6, 7}},
-		15: {ID: 15, AdjacentVertexes: [3]int{7, 14, 16}, TileIds: [3]int{3, 7}},
-		16: {ID: 16, AdjacentVertexes: [3]int{15, 26}, TileIds: [3]int{7}},
-		17: {ID: 17, AdjacentVertexes: [3]int{18, 28}, TileIds: [3]int{8}},
-		18: {ID: 18, AdjacentVertexes: [3]int{8, 17, 19}, TileIds: [3]int{4, 8}},
-		19: {ID: 19, AdjacentVertexes: [3]int{18, 20, 30}, TileIds: [3]int{4, 8, 9}},
-		20: {ID: 20, AdjacentVertexes: [3]int{10, 19, 21}, TileIds: [3]int{4, 5, 9}},
-		21: {ID: 21, AdjacentVertexes: [3]int{20, 22, 32}, TileIds: [3]int{5, 9, 10}},
-		22: {ID: 22, AdjacentVertexes: [3]int{12, 21, 23}, TileIds: [3]int{5, 6, 10}},
-		23: {ID: 23, AdjacentVertexes: [3]int{22, 24, 34}, TileIds: [3]int{6, 10, 11}},
-		24: {ID: 24, AdjacentVertexes: [3]int{14, 23, 25}, TileIds: [3]int{6, 7, 11}},
-		25: {ID: 25, AdjacentVertexes: [3]int{24, 26, 36}, TileIds: [3]int{7, 11, 12}},
-		26: {ID: 26, AdjacentVertexes: [3]int{16, 25, 27}, TileIds: [3]int{7, 12}},
-		27: {ID: 27, AdjacentVertexes: [3]int{26, 38}, TileIds: [3]int{12}},
-		28: {ID: 28, AdjacentVertexes: [3]int{17, 29}, TileIds: [3]int{8}},
-		29: {ID: 29, AdjacentVertexes: [3]int{28, 30, 39}, TileIds: [3]int{8, 13}},
-		30: {ID: 30, AdjacentVertexes: [3]int{19, 29, 31}, TileIds: [3]int{8, 9, 13}},
-		31: {ID: 31, AdjacentVertexes: [3]int{30, 32, 41}, TileIds: [3]int{9, 13, 14}},
-		32: {ID: 32, AdjacentVertexes: [3]int{21, 31, 33}, TileIds: [3]int{9, 10, 14}},
-		33: {ID: 33, AdjacentVertexes: [3]int{32, 34, 43}, TileIds: [3]int{10, 14, 15}},
-		34: {ID: 34, AdjacentVertexes: [3]int{23, 33, 35}, TileIds: [3]int{10, 11, 15}},
-		35: {ID: 35, AdjacentVertexes: [3]int{34, 36, 45}, TileIds: [3]int{11, 15, 16}},
-		36: {ID: 36, AdjacentVertexes: [3]int{25, 35, 37}, TileIds: [3]int{11, 12, 16}},
-		37: {ID: 37, AdjacentVertexes: [3]int{36, 38, 47}, TileIds: [3]int{12, 16}},
-		38: {ID: 38, AdjacentVertexes: [3]int{27, 37}, TileIds: [3]int{12}},
-		39: {ID: 39, AdjacentVertexes: [3]int{29, 40}, TileIds: [3]int{13}},
-		40: {ID: 40, AdjacentVertexes: [3]int{39, 41, 48}, TileIds: [3]int{13, 17}},
-		41: {ID: 41, AdjacentVertexes: [3]int{31, 40, 42}, TileIds: [3]int{13, 14, 17}},
-		42: {ID: 42, AdjacentVertexes: [3]int{41, 43, 50}, TileIds: [3]int{14, 17, 18}},
-		43: {ID: 43, AdjacentVertexes: [3]int{33, 42, 44}, TileIds: [3]int{14, 15, 18}},
-		44: {ID: 44, AdjacentVertexes: [3]int{43, 45, 52}, TileIds: [3]int{15, 18, 19}},
-		45: {ID: 45, AdjacentVertexes: [3]int{35, 44, 46}, TileIds: [3]int{15, 16, 19}},
-		46: {ID: 46, AdjacentVertexes: [3]int{45, 47, 54}, TileIds: [3]int{16, 19}},
-		47: {ID: 47, AdjacentVertexes: [3]int{37, 46}, TileIds: [3]int{16}},
-		48: {ID: 48, AdjacentVertexes: [3]int{40, 49}, TileIds: [3]int{17}},
-		49: {ID: 49, AdjacentVertexes: [3]int{48, 50}, TileIds: [3]int{17}},
-		50: {ID: 50, AdjacentVertexes: [3]int{42, 49, 51}, TileIds: [3]int{17, 18}},
-		51: {ID: 51, AdjacentVertexes: [3]int{50, 52}, TileIds: [3]int{18}},
-		52: {ID: 52, AdjacentVertexes: [3]int{44, 51, 53}, TileIds: [3]int{18, 19}},
-		53: {ID: 53, AdjacentVertexes: [3]int{52, 54}, TileIds: [3]int{19}},
-		54: {ID: 54, AdjacentVertexes: [3]int{46, 53}, TileIds: [3]int{19}},
-	}
-
-	edges := map[string]*Edge{}
-
-	return &Graph{
-		Vertices: vertices,
-		Edges:    edges,
+// Lays tokens in spiral order from the start tile, skipping the desert and sea
+func placeSpiralTokens(board *Board, tokens []int, start HexCoord) {
+	byCoord := make(map[HexCoord]*Tile, len(board.Tiles))
+	for _, tile := range board.Tiles {
+		byCoord[tile.Coord] = tile
+	}
+	next := 0
+	for _, coord := range SpiralOrder(board.Topology.Tiles, start) {
+		if tile := byCoord[coord]; tile.Produces() {
+			tile.NumberToken = tokens[next]
+			next++
+		}
+	}
+}
+
+// Lays shuffled resources over the board's land tiles in ID order, giving
+// each tile but the desert the next number token
+func placeTiles(board *Board, Resources []string, Tokens []int, sea map[HexCoord]bool) {
+	topology := board.Topology
+	board.Tiles = make([]*Tile, len(topology.Tiles))
+	tokenIndex, resourceIndex := 0, 0
+	for i, coord := range topology.Tiles {
+		tile := &Tile{
+			ID:       TileID(i + 1),
+			Coord:    coord,
+			Resource: Sea,
+		}
+		if !sea[coord] {
+			tile.Resource = Resources[resourceIndex]
+			resourceIndex++
+		}
+
+		if tile.Produces() {
+			tile.NumberToken = Tokens[tokenIndex]
+			tokenIndex++
+		} else if tile.Resource == "D" {
+			tile.NumberToken = 0           // Desert has no number
+			board.RobberPosition = tile.ID // Robber starts on desert
+		}
+
+		board.Tiles[i] = tile
 	}
 }
 
 func GenerateBank() *Bank {
+	return generateBank(BaseBoard, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+func generateBank(spec BoardSpec, rng *rand.Rand) *Bank {
 	bank := &Bank{
 		Resources: map[string]int{
-			"B": 19, // Brick
-			"L": 19, // Lumber
-			"S": 19, // Sheep
-			"W": 19, // Wheat
-			"O": 19, // Ore
+			"B": spec.BankResources, // Brick
+			"L": spec.BankResources, // Lumber
+			"S": spec.BankResources, // Sheep
+			"W": spec.BankResources, // Wheat
+			"O": spec.BankResources, // Ore
 		},
-		DevelopmentCards: make([]DevelopmentCard, 0, 25),
+		DevelopmentCards: make([]DevelopmentCard, 0, 34),
 	}
 
 	// Add development cards to the slice
-	cardCounts := map[string]int{
-		"Knight":         14,
-		"Victory Point":  5,
-		"Road Building":  2,
-		"Year of Plenty": 2,
-		"Monopoly":       2,
-	}
-
-	for cardType, count := range cardCounts {
-		for i := 0; i < count; i++ {
+	for _, cardType := range DevCardTypes { // Fixed order so seeded decks repeat
+		for i := 0; i < spec.DevCards[cardType]; i++ {
 			bank.DevelopmentCards = append(bank.DevelopmentCards, DevelopmentCard{Type: cardType})
 		}
 	}
 
-	shuffleDevCards(bank.DevelopmentCards)
+	shuffleSlice(bank.DevelopmentCards, rng)
 
 	return bank
 }
 
-func shuffleDevCards(cards []DevelopmentCard) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	r.Shuffle(len(cards), func(i, j int) {
-		cards[i], cards[j] = cards[j], cards[i]
-	})
-}
-
 func createPlayer(id int) Player {
 	return Player{ID: id, Resources: make(map[string]int), VictoryPoints: 0, DevelopmentCards: make(map[string]int)}
 }
 
-func shuffleSlice[T any](slice []T) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func shuffleSlice[T any](slice []T, r *rand.Rand) {
 	r.Shuffle(len(slice), func(i, j int) {
 		slice[i], slice[j] = slice[j], slice[i]
 	})
@@ -0,0 +1,321 @@
+// graph.go
+
+// The board graph is split in two: a layout of vertices, edges and tiles that
+// never changes during a game and is shared by every clone, and small arrays
+// saying who owns each vertex and edge. Owners are stored as the player's
+// position in CatanGame.Players plus one, with 0 meaning empty, so copying or
+// comparing a game never has to follow pointers.
+package gameplay
+
+import (
+	"fmt"
+	"sort"
+)
+
+type Graph struct {
+	layout          *graphLayout
+	vertexOwners    []uint8 // Indexed by vertex ID
+	vertexBuildings []uint8 // 0 empty, 1 settlement, 2 city
+	edgeOwners      []uint8 // Roads, indexed by edge index
+	shipOwners      []uint8 // Ships, indexed by edge index. An edge has a road or a ship, never both.
+}
+
+type graphLayout struct {
+	vertices    []Vertex // Indexed by vertex ID, entry 0 unused. Ownership fields are never set.
+	neighbors   [][]int  // Adjacent vertex IDs, sorted
+	vertexEdges [][]int  // Edge indexes touching each vertex
+	edges       [][2]int // Vertex IDs of each edge, lowest first
+}
+
+// NewGraph builds a graph with no buildings from vertex definitions, which
+// must be numbered from 1. Every edge is enumerated up front, numbered in
+// order of its lowest then highest vertex ID.
+func NewGraph(vertices map[int]*Vertex) *Graph {
+	layout := &graphLayout{
+		vertices:    make([]Vertex, len(vertices)+1),
+		neighbors:   make([][]int, len(vertices)+1),
+		vertexEdges: make([][]int, len(vertices)+1),
+	}
+
+	for id, vertex := range vertices {
+		layout.vertices[id] = Vertex{ID: id, AdjacentVertexes: vertex.AdjacentVertexes, TileIds: vertex.TileIds}
+	}
+	for id := 1; id <= len(vertices); id++ {
+		layout.neighbors[id] = append([]int(nil), layout.vertices[id].AdjacentVertexes...)
+		sort.Ints(layout.neighbors[id])
+		for _, adjID := range layout.neighbors[id] {
+			if id < adjID {
+				layout.edges = append(layout.edges, [2]int{id, adjID})
+			}
+		}
+	}
+	sort.Slice(layout.edges, func(i, j int) bool {
+		if layout.edges[i][0] != layout.edges[j][0] {
+			return layout.edges[i][0] < layout.edges[j][0]
+		}
+		return layout.edges[i][1] < layout.edges[j][1]
+	})
+	for index, edge := range layout.edges {
+		layout.vertexEdges[edge[0]] = append(layout.vertexEdges[edge[0]], index)
+		layout.vertexEdges[edge[1]] = append(layout.vertexEdges[edge[1]], index)
+	}
+
+	return &Graph{
+		layout:          layout,
+		vertexOwners:    make([]uint8, len(vertices)+1),
+		vertexBuildings: make([]uint8, len(vertices)+1),
+		edgeOwners:      make([]uint8, len(layout.edges)),
+		shipOwners:      make([]uint8, len(layout.edges)),
+	}
+}
+
+// Validate checks the layout is a sensible board: adjacency is symmetric, every
+// vertex has 2 or 3 neighbours and touches 1 to 3 tiles, every tile has 6
+// corners joined in a ring, and there are as many vertices, edges and tiles
+// as expected. Tile IDs must run from 1 to tiles.
+func (g *Graph) Validate(vertices, edges, tiles int) error {
+	if g.VertexCount() != vertices {
+		return fmt.Errorf("graph has %d vertices, expected %d", g.VertexCount(), vertices)
+	}
+	if g.EdgeCount() != edges {
+		return fmt.Errorf("graph has %d edges, expected %d", g.EdgeCount(), edges)
+	}
+
+	corners := make(map[TileID][]int)
+	for id := 1; id <= g.VertexCount(); id++ {
+		vertex := g.layout.vertices[id]
+		if vertex.ID != id {
+			return fmt.Errorf("vertex %d is missing", id)
+		}
+		if n := len(vertex.AdjacentVertexes); n < 2 || n > 3 {
+			return fmt.Errorf("vertex %d has %d neighbours, expected 2 or 3", id, n)
+		}
+		for _, adjID := range vertex.AdjacentVertexes {
+			if adjID == id || adjID < 1 || adjID > g.VertexCount() {
+				return fmt.Errorf("vertex %d has invalid neighbour %d", id, adjID)
+			}
+			if g.EdgeBetween(adjID, id) < 0 || !containsVertex(g.layout.vertices[adjID].AdjacentVertexes, id) {
+				return fmt.Errorf("vertex %d lists %d as a neighbour but not the other way round", id, adjID)
+			}
+		}
+		if n := len(vertex.TileIds); n < 1 || n > 3 {
+			return fmt.Errorf("vertex %d touches %d tiles, expected 1 to 3", id, n)
+		}
+		for _, tileID := range vertex.TileIds {
+			if tileID < 1 || int(tileID) > tiles {
+				return fmt.Errorf("vertex %d touches unknown tile %d", id, tileID)
+			}
+			corners[tileID] = append(corners[tileID], id)
+		}
+	}
+
+	for tileID := TileID(1); int(tileID) <= tiles; tileID++ {
+		if len(corners[tileID]) != 6 {
+			return fmt.Errorf("tile %d has %d corners, expected 6", tileID, len(corners[tileID]))
+		}
+		// Each corner must be joined to exactly two others of the same tile
+		for _, id := range corners[tileID] {
+			joined := 0
+			for _, other := range corners[tileID] {
+				if g.EdgeBetween(id, other) >= 0 {
+					joined++
+				}
+			}
+			if joined != 2 {
+				return fmt.Errorf("corner %d of tile %d joins %d of its other corners, expected 2", id, tileID, joined)
+			}
+		}
+	}
+	return nil
+}
+
+func containsVertex(vertexIDs []int, vertexID int) bool {
+	for _, id := range vertexIDs {
+		if id == vertexID {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Graph) VertexCount() int {
+	return len(g.layout.vertices) - 1
+}
+
+func (g *Graph) EdgeCount() int {
+	return len(g.layout.edges)
+}
+
+// Neighbors of a vertex, nil if it does not exist. Callers must not modify it.
+func (g *Graph) Neighbors(vertexID int) []int {
+	if vertexID < 1 || vertexID > g.VertexCount() {
+		return nil
+	}
+	return g.layout.neighbors[vertexID]
+}
+
+// EdgeVertices returns the two vertex IDs of an edge, lowest first
+func (g *Graph) EdgeVertices(edge int) (int, int) {
+	return g.layout.edges[edge][0], g.layout.edges[edge][1]
+}
+
+// EdgeBetween returns the index of the edge joining two vertices, or -1
+func (g *Graph) EdgeBetween(vertexID1, vertexID2 int) int {
+	if vertexID1 < 1 || vertexID1 > g.VertexCount() {
+		return -1
+	}
+	for _, edge := range g.layout.vertexEdges[vertexID1] {
+		if a, b := g.EdgeVertices(edge); (a == vertexID1 && b == vertexID2) || (b == vertexID1 && a == vertexID2) {
+			return edge
+		}
+	}
+	return -1
+}
+
+// Clone copies the ownership arrays and shares the layout
+func (g *Graph) Clone() *Graph {
+	return &Graph{
+		layout:          g.layout,
+		vertexOwners:    append([]uint8(nil), g.vertexOwners...),
+		vertexBuildings: append([]uint8(nil), g.vertexBuildings...),
+		edgeOwners:      append([]uint8(nil), g.edgeOwners...),
+		shipOwners:      append([]uint8(nil), g.shipOwners...),
+	}
+}
+
+// Equal reports whether two graphs have the same layout and the same pieces
+func (g *Graph) Equal(other *Graph) bool {
+	return g.layout.equal(other.layout) &&
+		string(g.vertexOwners) == string(other.vertexOwners) &&
+		string(g.vertexBuildings) == string(other.vertexBuildings) &&
+		string(g.edgeOwners) == string(other.edgeOwners) &&
+		string(g.shipOwners) == string(other.shipOwners)
+}
+
+// Layouts are shared by clones, but a board built again, say from a saved
+// game, gets an equal layout of its own
+func (l *graphLayout) equal(other *graphLayout) bool {
+	if l == other {
+		return true
+	}
+	if len(l.vertices) != len(other.vertices) || len(l.edges) != len(other.edges) {
+		return false
+	}
+	for i, edge := range l.edges {
+		if edge != other.edges[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Position of a player in game.Players plus one, as stored in the graph.
+// Neutral players come after everyone in game.Players.
+func playerSlot(game *CatanGame, player *Player) uint8 {
+	for i, p := range game.Players {
+		if p == player {
+			return uint8(i + 1)
+		}
+	}
+	for i, p := range game.Neutrals {
+		if p == player {
+			return uint8(len(game.Players) + i + 1)
+		}
+	}
+	return 0
+}
+
+func slotPlayer(game *CatanGame, slot uint8) *Player {
+	if slot == 0 {
+		return nil
+	}
+	if int(slot) > len(game.Players) {
+		return game.Neutrals[int(slot)-len(game.Players)-1]
+	}
+	return game.Players[slot-1]
+}
+
+// VertexOwner is the player with a building on the vertex, nil if empty
+func VertexOwner(game *CatanGame, vertexID int) *Player {
+	return slotPlayer(game, game.Board.Graph.vertexOwners[vertexID])
+}
+
+// VertexBuilding is 0 for an empty vertex, 1 for a settlement and 2 for a city
+func VertexBuilding(game *CatanGame, vertexID int) int {
+	return int(game.Board.Graph.vertexBuildings[vertexID])
+}
+
+// RoadOwner is the player with a road between two vertices, nil if there is
+// no road or the vertices are not adjacent
+func RoadOwner(game *CatanGame, vertexID1, vertexID2 int) *Player {
+	edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2)
+	if edge < 0 {
+		return nil
+	}
+	return slotPlayer(game, game.Board.Graph.edgeOwners[edge])
+}
+
+// ShipOwner is the player with a ship between two vertices, nil if there is none
+func ShipOwner(game *CatanGame, vertexID1, vertexID2 int) *Player {
+	edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2)
+	if edge < 0 {
+		return nil
+	}
+	return slotPlayer(game, game.Board.Graph.shipOwners[edge])
+}
+
+// GetEdge returns a snapshot of the edge between two vertices, nil if they are not adjacent
+func GetEdge(game *CatanGame, vertexID1, vertexID2 int) *Edge {
+	edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2)
+	if edge < 0 {
+		return nil
+	}
+	return edgeSnapshot(game, edge)
+}
+
+// Roads returns a snapshot of every edge with a road on it
+func Roads(game *CatanGame) []*Edge {
+	var roads []*Edge
+	for edge, slot := range game.Board.Graph.edgeOwners {
+		if slot != 0 {
+			roads = append(roads, edgeSnapshot(game, edge))
+		}
+	}
+	return roads
+}
+
+// Ships returns a snapshot of every edge with a ship on it
+func Ships(game *CatanGame) []*Edge {
+	var ships []*Edge
+	for edge, slot := range game.Board.Graph.shipOwners {
+		if slot != 0 {
+			snapshot := edgeSnapshot(game, edge)
+			snapshot.OccupiedBy = slotPlayer(game, slot)
+			ships = append(ships, snapshot)
+		}
+	}
+	return ships
+}
+
+func edgeSnapshot(game *CatanGame, edge int) *Edge {
+	a, b := game.Board.Graph.EdgeVertices(edge)
+	return &Edge{
+		ID:         fmt.Sprintf("%d-%d", a, b),
+		OccupiedBy: slotPlayer(game, game.Board.Graph.edgeOwners[edge]),
+		Vertices:   [2]*Vertex{GetVertexByID(game, a), GetVertexByID(game, b)},
+	}
+}
+
+func setBuilding(game *CatanGame, vertexID int, player *Player, building int) {
+	game.Board.Graph.vertexOwners[vertexID] = playerSlot(game, player)
+	game.Board.Graph.vertexBuildings[vertexID] = uint8(building)
+}
+
+func setRoad(game *CatanGame, edge int, player *Player) {
+	game.Board.Graph.edgeOwners[edge] = playerSlot(game, player)
+}
+
+// setShip places a ship, or removes one when player is nil
+func setShip(game *CatanGame, edge int, player *Player) {
+	game.Board.Graph.shipOwners[edge] = playerSlot(game, player)
+}
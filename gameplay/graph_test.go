@@ -0,0 +1,111 @@
+package gameplay
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBaseBoardTopology(t *testing.T) {
+	_, graph := GenerateTopology(HexagonShape(2))
+	if err := graph.Validate(54, 72, 19); err != nil {
+		t.Fatal(err)
+	}
+
+	// Vertex IDs are what players type, so pin the numbering down
+	known := map[int]struct {
+		neighbors []int
+		tiles     []TileID
+	}{
+		1:  {[]int{2, 9}, []TileID{1}},
+		11: {[]int{3, 10, 12}, []TileID{1, 2, 5}},
+		20: {[]int{10, 19, 21}, []TileID{4, 5, 9}},
+		38: {[]int{27, 37}, []TileID{12}},
+		54: {[]int{46, 53}, []TileID{19}},
+	}
+	for id, want := range known {
+		vertex := graph.layout.vertices[id]
+		if fmt.Sprint(graph.Neighbors(id)) != fmt.Sprint(want.neighbors) || fmt.Sprint(vertex.TileIds) != fmt.Sprint(want.tiles) {
+			t.Errorf("vertex %d has neighbours %v and tiles %v, expected %v and %v",
+				id, graph.Neighbors(id), vertex.TileIds, want.neighbors, want.tiles)
+		}
+	}
+}
+
+func TestShapesGiveSameBaseBoard(t *testing.T) {
+	hexagon, _ := GenerateTopology(HexagonShape(2))
+	rows, _ := GenerateTopology(RowsShape(3, 4, 5, 4, 3))
+	if fmt.Sprint(hexagon) != fmt.Sprint(rows) {
+		t.Errorf("HexagonShape(2) and RowsShape(3, 4, 5, 4, 3) give different boards")
+	}
+}
+
+func TestValidateRejectsOneSidedNeighbour(t *testing.T) {
+	vertices := map[int]*Vertex{}
+	_, graph := GenerateTopology(HexagonShape(2))
+	for id, v := range graph.layout.vertices[1:] {
+		copied := v
+		vertices[id+1] = &copied
+	}
+	vertices[1] = &Vertex{ID: 1, AdjacentVertexes: []int{9, 2, 3}, TileIds: []TileID{1}}
+
+	if err := NewGraph(vertices).Validate(54, 73, 19); err == nil {
+		t.Fatal("expected an error for 1-3 only being listed by vertex 1")
+	}
+}
+
+// Every vertex's TileIds must resolve to the tiles with those IDs, including
+// tile 19 which used to fall off the end of the 0-based numbering
+func TestVertexTilesResolve(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	for id := 1; id <= game.Board.Graph.VertexCount(); id++ {
+		vertex := GetVertexByID(game, id)
+		tiles := vertexTiles(game, id)
+		if len(tiles) != len(vertex.TileIds) {
+			t.Fatalf("vertex %d resolves %d of its %d tiles", id, len(tiles), len(vertex.TileIds))
+		}
+		for i, tile := range tiles {
+			if tile.ID != vertex.TileIds[i] || tile != game.Board.Tiles[tile.ID-1] {
+				t.Errorf("vertex %d tile %d resolved to tile %d", id, vertex.TileIds[i], tile.ID)
+			}
+		}
+	}
+	if tile := GetTileByID(game, 19); tile == nil || tile.ID != 19 {
+		t.Errorf("GetTileByID(19) = %v", tile)
+	}
+	if tile := GetTileByID(game, 0); tile != nil {
+		t.Errorf("GetTileByID(0) = %v, expected nil", tile)
+	}
+	if robber := GetTileByID(game, game.Board.RobberPosition); robber == nil || robber.Resource != "D" {
+		t.Errorf("robber starts on %v, expected the desert", robber)
+	}
+}
+
+// The second setup settlement collects one card from each tile around it
+func TestSetupPaysFromVertexTiles(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	BeginSetup(game, game.Players[0])
+	placements := []int{1, 7, 48, 54, 20, 34} // Last two are vertices touching three tiles
+	for _, vertexID := range placements {
+		player := CurrentPlayer(game)
+		if err := ApplyAction(game, Action{Type: ActionSetupSettlement, PlayerID: player.ID, VertexID: vertexID}, nil); err != nil {
+			t.Fatal(err)
+		}
+		road := LegalActions(game)[0]
+		if err := ApplyAction(game, road, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, vertexID := range placements[3:] {
+		player := GetPlayerByID(game, game.SetupOrder[3+i])
+		expected := make(map[string]int)
+		for _, tileID := range GetVertexByID(game, vertexID).TileIds {
+			if resource := GetTileByID(game, tileID).Resource; resource != "D" {
+				expected[resource]++
+			}
+		}
+		if !equalCounts(player.Resources, expected) {
+			t.Errorf("Player %d got %v for vertex %d, expected %v", player.ID, player.Resources, vertexID, expected)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// hex.go
+
+// Boards are built from the axial coordinates of their tiles, so any shape of
+// board gets its vertices, edges and adjacency worked out the same way.
+//
+// Tiles are pointy topped. Axial Q runs east and R runs south-east; the cube
+// coordinate S = -Q-R is implied. Vertices sit on a lattice where a tile's
+// centre is at X = 2Q+R, Y = 3R and its six corners are at (X, Y-2),
+// (X+1, Y-1), (X+1, Y+1), (X, Y+2), (X-1, Y+1) and (X-1, Y-1).
+package gameplay
+
+import (
+	"math"
+	"sort"
+)
+
+type HexCoord struct {
+	Q, R int
+}
+
+func (h HexCoord) S() int {
+	return -h.Q - h.R
+}
+
+// Neighbours in the order east, south-east, south-west, west, north-west, north-east
+var hexDirections = []HexCoord{{1, 0}, {0, 1}, {-1, 1}, {-1, 0}, {0, -1}, {1, -1}}
+
+func (h HexCoord) Neighbor(direction int) HexCoord {
+	d := hexDirections[direction%6]
+	return HexCoord{h.Q + d.Q, h.R + d.R}
+}
+
+// Distance is the number of tile steps between two tiles
+func (h HexCoord) Distance(other HexCoord) int {
+	return (abs(h.Q-other.Q) + abs(h.R-other.R) + abs(h.S()-other.S())) / 2
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// VertexPos is a vertex's place on the lattice described at the top of the file
+type VertexPos struct {
+	X, Y int
+}
+
+func (h HexCoord) corners() [6]VertexPos {
+	x, y := 2*h.Q+h.R, 3*h.R
+	return [6]VertexPos{{x, y - 2}, {x + 1, y - 1}, {x + 1, y + 1}, {x, y + 2}, {x - 1, y + 1}, {x - 1, y - 1}}
+}
+
+// Row of the zigzag a vertex belongs to. The corners along the top of tile
+// row R are vertex row R, those along its bottom are row R+1.
+func (p VertexPos) row() int {
+	return floorDiv(p.Y+2, 3)
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 && a%b != 0 {
+		return a/b - 1
+	}
+	return a / b
+}
+
+// HexagonShape is every tile within radius steps of the centre; radius 2 is
+// the 19 tile base game board
+func HexagonShape(radius int) []HexCoord {
+	var shape []HexCoord
+	for r := -radius; r <= radius; r++ {
+		for q := -radius; q <= radius; q++ {
+			if (HexCoord{q, r}).Distance(HexCoord{}) <= radius {
+				shape = append(shape, HexCoord{q, r})
+			}
+		}
+	}
+	return shape
+}
+
+// RowsShape lays out rows of the given lengths from north to south, each row
+// centred on the same line, like the 3-4-5-4-3 base board
+func RowsShape(lengths ...int) []HexCoord {
+	var shape []HexCoord
+	for i, length := range lengths {
+		r := i - len(lengths)/2
+		for j := 0; j < length; j++ {
+			x := -(length - 1) + 2*j // Centre X on the lattice
+			shape = append(shape, HexCoord{Q: floorDiv(x-r, 2), R: r})
+		}
+	}
+	return shape
+}
+
+// ShapeCorners are the tiles of a shape with at most three neighbours in it,
+// the six corners of the usual boards
+func ShapeCorners(shape []HexCoord) []HexCoord {
+	in := make(map[HexCoord]bool, len(shape))
+	for _, tile := range shape {
+		in[tile] = true
+	}
+	var corners []HexCoord
+	for _, tile := range shape {
+		neighbours := 0
+		for direction := range hexDirections {
+			if in[tile.Neighbor(direction)] {
+				neighbours++
+			}
+		}
+		if neighbours <= 3 {
+			corners = append(corners, tile)
+		}
+	}
+	return corners
+}
+
+// SpiralOrder walks a shape the way number tokens are laid out by hand: from
+// start, counterclockwise round the outside, then round each ring inside it,
+// ending in the middle. Each ring starts level with where the one outside it
+// started.
+func SpiralOrder(shape []HexCoord, start HexCoord) []HexCoord {
+	remaining := make(map[HexCoord]bool, len(shape))
+	var centreX, centreY float64
+	for _, tile := range shape {
+		remaining[tile] = true
+		x, y := tile.centre()
+		centreX += x / float64(len(shape))
+		centreY += y / float64(len(shape))
+	}
+	angle := func(tile HexCoord) float64 {
+		x, y := tile.centre()
+		return math.Atan2(centreY-y, x-centreX) // Y flipped so angles grow counterclockwise on screen
+	}
+	// How far counterclockwise of from a tile is
+	turn := func(from float64, tile HexCoord) float64 {
+		return math.Mod(angle(tile)-from+4*math.Pi, 2*math.Pi)
+	}
+
+	startAngle := angle(start)
+	var order []HexCoord
+	for len(remaining) > 0 {
+		var ring []HexCoord
+		for _, tile := range shape {
+			if !remaining[tile] {
+				continue
+			}
+			for direction := range hexDirections {
+				if !remaining[tile.Neighbor(direction)] {
+					ring = append(ring, tile)
+					break
+				}
+			}
+		}
+		if len(order) > 0 {
+			// Start level with the outer ring, measuring either way round
+			best := ring[0]
+			for _, tile := range ring {
+				if math.Min(turn(startAngle, tile), 2*math.Pi-turn(startAngle, tile)) <
+					math.Min(turn(startAngle, best), 2*math.Pi-turn(startAngle, best)) {
+					best = tile
+				}
+			}
+			startAngle = angle(best) - 1e-9
+		}
+		sort.SliceStable(ring, func(i, j int) bool {
+			return turn(startAngle, ring[i]) < turn(startAngle, ring[j])
+		})
+		for _, tile := range ring {
+			delete(remaining, tile)
+		}
+		order = append(order, ring...)
+	}
+	return order
+}
+
+// Position of the tile's centre on the page, with tiles one unit across
+func (h HexCoord) centre() (float64, float64) {
+	return math.Sqrt(3) * (float64(h.Q) + float64(h.R)/2), 1.5 * float64(h.R)
+}
+
+// Topology is a board shape worked out into numbered tiles and vertices. It
+// never changes during a game and is shared by every clone.
+type Topology struct {
+	Tiles      []HexCoord  // Coordinates of tile ID i+1
+	Vertices   []VertexPos // Position of vertex ID i+1
+	TileRows   [][]TileID  // Tile IDs by row, north to south
+	VertexRows [][]int     // Vertex IDs by zigzag row, north to south
+}
+
+// GenerateTopology numbers the tiles of a shape in reading order (by R, then
+// Q) and the vertices row by row, west to east, both starting from 1, and
+// builds an empty graph joining them
+func GenerateTopology(shape []HexCoord) (*Topology, *Graph) {
+	tiles := append([]HexCoord(nil), shape...)
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].R != tiles[j].R {
+			return tiles[i].R < tiles[j].R
+		}
+		return tiles[i].Q < tiles[j].Q
+	})
+
+	seen := make(map[VertexPos]bool)
+	var positions []VertexPos
+	for _, tile := range tiles {
+		for _, corner := range tile.corners() {
+			if !seen[corner] {
+				seen[corner] = true
+				positions = append(positions, corner)
+			}
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].row() != positions[j].row() {
+			return positions[i].row() < positions[j].row()
+		}
+		return positions[i].X < positions[j].X
+	})
+
+	ids := make(map[VertexPos]int, len(positions))
+	vertices := make(map[int]*Vertex, len(positions))
+	for i, pos := range positions {
+		ids[pos] = i + 1
+		vertices[i+1] = &Vertex{ID: i + 1}
+	}
+
+	topology := &Topology{Tiles: tiles, Vertices: positions}
+	for i, tile := range tiles {
+		tileID := TileID(i + 1)
+		if len(topology.TileRows) == 0 || tiles[i-1].R != tile.R {
+			topology.TileRows = append(topology.TileRows, nil)
+		}
+		topology.TileRows[len(topology.TileRows)-1] = append(topology.TileRows[len(topology.TileRows)-1], tileID)
+
+		corners := tile.corners()
+		for c, corner := range corners {
+			vertex := vertices[ids[corner]]
+			vertex.TileIds = append(vertex.TileIds, tileID)
+			for _, next := range []VertexPos{corners[(c+1)%6], corners[(c+5)%6]} {
+				if !containsVertex(vertex.AdjacentVertexes, ids[next]) {
+					vertex.AdjacentVertexes = append(vertex.AdjacentVertexes, ids[next])
+				}
+			}
+		}
+	}
+	for i, pos := range positions {
+		sort.Ints(vertices[i+1].AdjacentVertexes)
+		if i == 0 || positions[i-1].row() != pos.row() {
+			topology.VertexRows = append(topology.VertexRows, nil)
+		}
+		topology.VertexRows[len(topology.VertexRows)-1] = append(topology.VertexRows[len(topology.VertexRows)-1], i+1)
+	}
+
+	return topology, NewGraph(vertices)
+}
@@ -0,0 +1,45 @@
+// hotSeat.go
+
+// Keeping hands private when players take turns at one terminal. Whenever a
+// different player has to act, the screen is cleared and the game waits for
+// them to take the seat before showing their cards. Everything else on show,
+// the board, points and card counts, is public anyway.
+package gameplay
+
+import "fmt"
+
+// Also clears what has scrolled off the top, so earlier hands cannot be
+// scrolled back to
+const clearScrollback = "\033[3J"
+
+// handOver clears the screen and asks for the terminal to be passed on when
+// it is a different player's turn to act, then shows them the board and
+// their hand. It does nothing unless CLIGame.HotSeat is set.
+func (cg *CLIGame) handOver(game *CatanGame) error {
+	player := CurrentPlayer(game)
+	if !cg.HotSeat || player.ID == cg.shown {
+		return nil
+	}
+	cg.shown = 0
+	prompt := fmt.Sprintf("Pass to %s, press ENTER ", player)
+	var err error
+	if cg.screen != nil {
+		cg.screen.hidden = true
+		err = cg.waitForEnter(prompt)
+		cg.screen.hidden = false
+	} else {
+		fmt.Fprint(cg.out(), clearScreen+clearScrollback)
+		err = cg.waitForEnter(prompt)
+		fmt.Fprint(cg.out(), clearScreen+clearScrollback)
+	}
+	if err != nil {
+		return err
+	}
+
+	cg.shown = player.ID
+	if cg.screen == nil {
+		fmt.Fprint(cg.out(), RenderBoard(game, true))
+		printHand(cg.out(), player)
+	}
+	return nil
+}
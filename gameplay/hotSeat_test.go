@@ -0,0 +1,47 @@
+package gameplay
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Each player's cards are only shown after the terminal has been passed to
+// them, and the screen is cleared on the way
+func TestHotSeat(t *testing.T) {
+	game := setUpGame(t)
+	var output strings.Builder
+	cg := &CLIGame{
+		Input:    strings.NewReader("\nend\n\nscore\n"),
+		Output:   &output,
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		HotSeat:  true,
+	}
+	game.HasRolled = true // So the first turn can end straight away
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+
+	text := output.String()
+	pass1 := strings.Index(text, "Pass to Player 1")
+	pass2 := strings.Index(text, "Pass to Player 2")
+	hand1 := strings.Index(text, "Player 1 has")
+	hand2 := strings.Index(text, "Player 2 has")
+	if pass1 < 0 || pass2 < 0 || !(pass1 < hand1 && hand1 < pass2 && pass2 < hand2) {
+		t.Fatalf("hands and passes are out of order:\n%s", text)
+	}
+	if !strings.Contains(text[hand1:pass2], "end turn") || !strings.Contains(text[hand1:pass2], clearScreen+clearScrollback) {
+		t.Error("the screen was not cleared after Player 1's turn")
+	}
+	if after := text[pass2:]; strings.Contains(after, describeCards(game.Players[0].Resources)+")") {
+		t.Error("Player 1's cards were shown to Player 2")
+	}
+
+	// Open hands show everyone's cards in the scores
+	var scores strings.Builder
+	printScores(&scores, game, true)
+	if !strings.Contains(scores.String(), "("+describeCards(game.Players[0].Resources)+")") {
+		t.Errorf("open hands are not shown in the scores:\n%s", scores.String())
+	}
+}
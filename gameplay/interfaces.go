@@ -2,7 +2,7 @@
 package gameplay
 
 type GameInitializer interface {
-	Initialize() int
+	Initialize() (int, error)
 }
 
 type GameStarter interface {
@@ -12,3 +12,8 @@ type GameStarter interface {
 type PlayerSelector interface {
 	SelectStartingPlayer(game *CatanGame) *Player
 }
+
+// Agent decides moves for a player, given the moves LegalActions allows
+type Agent interface {
+	ChooseAction(game *CatanGame, legal []Action) Action
+}
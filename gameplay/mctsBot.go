@@ -0,0 +1,258 @@
+// mctsBot.go
+
+// A Monte Carlo tree search player. Each iteration copies the game, reshuffles
+// whatever the bot cannot see, walks down the search tree picking moves by
+// UCT, then plays the rest of the game out with GreedyBot. Because dice and
+// hidden cards change every iteration the tree is keyed by moves only
+// ("open loop"), and a child's statistics only count iterations where its
+// move was legal.
+package gameplay
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+type MCTSBot struct {
+	Rand         *rand.Rand
+	Iterations   int           // Stop after this many iterations, 0 for no limit
+	TimeLimit    time.Duration // Stop after this long, 0 for no limit
+	RolloutDepth int           // Moves played in each rollout before scoring the position
+	Exploration  float64       // UCT exploration constant
+}
+
+// NewMCTSBot returns a bot with a budget of iterations per move
+func NewMCTSBot(rng *rand.Rand, iterations int) *MCTSBot {
+	return &MCTSBot{
+		Rand:         rng,
+		Iterations:   iterations,
+		RolloutDepth: 200,
+		Exploration:  0.7,
+	}
+}
+
+type mctsNode struct {
+	action   Action
+	playerID int // Player who made action
+	parent   *mctsNode
+	children []*mctsNode
+
+	visits int
+	avail  int     // Iterations where this node's action was legal
+	reward float64 // Summed rewards for playerID
+}
+
+// ChooseAction searches for the budget given, then plays the most visited
+// move. If the budget ran out before a single iteration it plays the
+// rollout policy's move instead, as it does for a negative budget.
+func (b *MCTSBot) ChooseAction(game *CatanGame, legal []Action) Action {
+	if len(legal) == 1 {
+		return legal[0]
+	}
+
+	me := CurrentPlayer(game).ID
+	rollout := &GreedyBot{Rand: b.Rand, Epsilon: 0.2}
+	root := &mctsNode{}
+	if b.Iterations < 0 || b.TimeLimit < 0 {
+		return rollout.ChooseAction(game, legal)
+	}
+	deadline := time.Now().Add(b.TimeLimit)
+
+	for i := 0; b.Iterations == 0 || i < b.Iterations; i++ {
+		if b.TimeLimit > 0 && time.Now().After(deadline) {
+			break
+		}
+		if b.Iterations == 0 && b.TimeLimit == 0 && i >= 1000 {
+			break // No budget given, don't run forever
+		}
+
+		state := game.Clone()
+		Determinize(state, me, b.Rand)
+
+		// Selection and expansion
+		node := root
+		for state.Phase != "finished" {
+			options := legal
+			if node != root {
+				options = LegalActions(state)
+			}
+			child, expanded := b.selectChild(node, options, CurrentPlayer(state).ID)
+			applyAction(state, child.action, b.Rand)
+			node = child
+			if expanded {
+				break
+			}
+		}
+
+		// Rollout
+		for depth := 0; depth < b.RolloutDepth && state.Phase != "finished"; depth++ {
+			applyAction(state, rollout.ChooseAction(state, LegalActions(state)), b.Rand)
+		}
+
+		// Backpropagation
+		rewards := scorePosition(state)
+		for ; node != root; node = node.parent {
+			node.visits++
+			node.reward += rewards[node.playerID]
+		}
+		root.visits++
+	}
+
+	if len(root.children) == 0 {
+		return rollout.ChooseAction(game, legal)
+	}
+	best := root.children[0]
+	for _, child := range root.children {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.action
+}
+
+// Returns an untried legal move as a new child if there is one, otherwise the
+// legal child with the best UCT score. The bool reports whether it expanded.
+func (b *MCTSBot) selectChild(node *mctsNode, options []Action, playerID int) (*mctsNode, bool) {
+	existing := make(map[Action]*mctsNode, len(node.children))
+	for _, child := range node.children {
+		existing[child.action] = child
+	}
+
+	var untried []Action
+	var available []*mctsNode
+	for _, action := range options {
+		if child, ok := existing[action]; ok {
+			child.avail++
+			available = append(available, child)
+		} else {
+			untried = append(untried, action)
+		}
+	}
+
+	if len(untried) > 0 {
+		child := &mctsNode{
+			action:   untried[b.Rand.Intn(len(untried))],
+			playerID: playerID,
+			parent:   node,
+			avail:    1,
+		}
+		node.children = append(node.children, child)
+		return child, true
+	}
+
+	best := available[0]
+	bestScore := math.Inf(-1)
+	for _, child := range available {
+		score := child.reward/float64(child.visits) +
+			b.Exploration*math.Sqrt(math.Log(float64(child.avail))/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best, false
+}
+
+// Rewards per player ID: 1 for the winner of a finished game, otherwise a
+// share of the points needed to win, with a bonus for whoever leads
+func scorePosition(game *CatanGame) map[int]float64 {
+	rewards := make(map[int]float64, len(game.Players))
+	if game.WinnerID != 0 {
+		rewards[game.WinnerID] = 1
+		return rewards
+	}
+
+	leader := 0
+	for _, player := range game.Players {
+		if TotalVictoryPoints(player) > leader {
+			leader = TotalVictoryPoints(player)
+		}
+	}
+	for _, player := range game.Players {
+		points := TotalVictoryPoints(player)
+		rewards[player.ID] = 0.8 * float64(points) / VictoryPointsToWin
+		if points == leader {
+			rewards[player.ID] += 0.2
+		}
+	}
+	return rewards
+}
+
+// Determinize replaces what the given player cannot know with one random
+// guess: the order of the development card deck, which development cards
+// the other players hold and which resources are in their hands. Everyone
+// keeps the same number of cards, and of cards bought this turn.
+func Determinize(game *CatanGame, playerID int, rng *rand.Rand) {
+	determinizeResources(game, playerID, rng)
+
+	var pool []DevelopmentCard
+	counts := make(map[int]int)
+	bought := make(map[int]int)
+
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			continue
+		}
+		for _, cardType := range DevCardTypes {
+			for i := 0; i < player.DevelopmentCards[cardType]; i++ {
+				pool = append(pool, DevelopmentCard{Type: cardType})
+			}
+			counts[player.ID] += player.DevelopmentCards[cardType]
+		}
+		for _, count := range player.BoughtThisTurn {
+			bought[player.ID] += count
+		}
+	}
+	pool = append(pool, game.Bank.DevelopmentCards...)
+	rng.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			continue
+		}
+		player.DevelopmentCards = make(map[string]int)
+		player.BoughtThisTurn = make(map[string]int)
+		for i := 0; i < counts[player.ID]; i++ {
+			player.DevelopmentCards[pool[0].Type]++
+			if i < bought[player.ID] {
+				player.BoughtThisTurn[pool[0].Type]++
+			}
+			pool = pool[1:]
+		}
+	}
+	game.Bank.DevelopmentCards = pool
+}
+
+// Deals the other players' resource cards out again at random, each
+// player getting as many as they held
+func determinizeResources(game *CatanGame, playerID int, rng *rand.Rand) {
+	var pool []string
+	sizes := make(map[int]int)
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			continue
+		}
+		for _, resource := range ResourceTypes {
+			for i := 0; i < player.Resources[resource]; i++ {
+				pool = append(pool, resource)
+			}
+			sizes[player.ID] += player.Resources[resource]
+		}
+	}
+	rng.Shuffle(len(pool), func(i, j int) {
+		pool[i], pool[j] = pool[j], pool[i]
+	})
+
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			continue
+		}
+		player.Resources = make(map[string]int)
+		for _, resource := range pool[:sizes[player.ID]] {
+			player.Resources[resource]++
+		}
+		pool = pool[sizes[player.ID]:]
+	}
+}
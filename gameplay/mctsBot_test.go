@@ -0,0 +1,93 @@
+package gameplay
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func contains(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Every bot picks one of the moves it is offered, whatever the phase
+func TestBotsChooseLegalMoves(t *testing.T) {
+	for _, name := range []string{"random", "greedy", "mcts:10"} {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(4))
+			bot, err := NewAgent(name, rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			game := NewSeededCatanGame([]int{1, 2, 3}, 4)
+			BeginSetup(game, game.Players[0])
+			for moves := 0; moves < 300 && game.Phase != "finished"; moves++ {
+				legal := LegalActions(game)
+				action := bot.ChooseAction(game, legal)
+				if !contains(legal, action) {
+					t.Fatalf("move %d in the %s phase: %s is not one of %v", moves, game.Phase, action, legal)
+				}
+				applyAction(game, action, rng)
+			}
+		})
+	}
+}
+
+func TestMCTSBudget(t *testing.T) {
+	game := setUpGame(t)
+	applyAction(game, Action{Type: ActionRoll, PlayerID: 1}, rollingSource(t, 8))
+	legal := LegalActions(game)
+
+	// Out of time before the first iteration, so the rollout policy picks
+	bot := &MCTSBot{Rand: rand.New(rand.NewSource(1)), TimeLimit: time.Nanosecond, RolloutDepth: 10}
+	if action := bot.ChooseAction(game, legal); !contains(legal, action) {
+		t.Errorf("%s is not legal", action)
+	}
+
+	// A negative budget searches nothing either
+	for _, bot := range []*MCTSBot{
+		NewMCTSBot(rand.New(rand.NewSource(1)), -1),
+		{Rand: rand.New(rand.NewSource(1)), TimeLimit: -time.Second, RolloutDepth: 10},
+	} {
+		if action := bot.ChooseAction(game, legal); !contains(legal, action) {
+			t.Errorf("%s is not legal", action)
+		}
+	}
+}
+
+// Hidden cards are dealt again, but nobody's hand changes size and the
+// player's own hand is left as it is
+func TestDeterminize(t *testing.T) {
+	game := setUpGame(t)
+	game.Players[0].Resources = map[string]int{"B": 2, "O": 1}
+	game.Players[1].Resources = map[string]int{"W": 4}
+	game.Players[2].Resources = map[string]int{"L": 3, "S": 1}
+	game.Players[1].DevelopmentCards["Knight"] = 2
+
+	changed := false
+	for seed := int64(1); seed <= 10; seed++ {
+		state := game.Clone()
+		Determinize(state, 1, rand.New(rand.NewSource(seed)))
+		for i, player := range state.Players {
+			if handSize(player) != handSize(game.Players[i]) || devCardCount(player) != devCardCount(game.Players[i]) {
+				t.Fatalf("player %d holds %d and %d cards, expected %d and %d", player.ID,
+					handSize(player), devCardCount(player), handSize(game.Players[i]), devCardCount(game.Players[i]))
+			}
+		}
+		if state.Players[0].Resources["B"] != 2 || state.Players[0].Resources["O"] != 1 {
+			t.Fatalf("player 1's own hand changed to %v", state.Players[0].Resources)
+		}
+		if len(state.Bank.DevelopmentCards) != len(game.Bank.DevelopmentCards) {
+			t.Fatalf("the deck has %d cards, expected %d", len(state.Bank.DevelopmentCards), len(game.Bank.DevelopmentCards))
+		}
+		changed = changed || state.Players[1].Resources["W"] != 4
+	}
+	if !changed {
+		t.Error("player 2's hand was never dealt again")
+	}
+}
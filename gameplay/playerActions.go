@@ -1,2 +1,842 @@
 // playerActions.go
+
+// The rules engine: every move a player can make is an Action, LegalActions
+// lists the moves open to whoever acts next and ApplyAction carries one out.
+// Frontends and bots both drive the game through these two functions.
 package gameplay
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+const VictoryPointsToWin = 10
+
+// Resource types in the order they are listed everywhere
+var ResourceTypes = []string{"B", "L", "S", "W", "O"}
+
+var DevCardTypes = []string{"Knight", "Victory Point", "Road Building", "Year of Plenty", "Monopoly"}
+
+type ActionType int
+
+const (
+	ActionSetupSettlement ActionType = iota
+	ActionSetupRoad
+	ActionRoll
+	ActionBuildRoad
+	ActionBuildSettlement
+	ActionBuildCity
+	ActionBuyDevCard
+	ActionPlayKnight
+	ActionPlayRoadBuilding
+	ActionPlayYearOfPlenty
+	ActionPlayMonopoly
+	ActionMoveRobber
+	ActionBankTrade
+	ActionEndTurn
+	ActionNeutralRoad
+	ActionNeutralSettlement
+	ActionSetupShip
+	ActionBuildShip
+	ActionMoveShip
+	ActionForcedTrade
+	ActionGiveBack
+	ActionReturnRobber
+)
+
+var actionNames = map[ActionType]string{
+	ActionSetupSettlement:   "setup settlement",
+	ActionSetupRoad:         "setup road",
+	ActionRoll:              "roll",
+	ActionBuildRoad:         "build road",
+	ActionBuildSettlement:   "build settlement",
+	ActionBuildCity:         "build city",
+	ActionBuyDevCard:        "buy development card",
+	ActionPlayKnight:        "play knight",
+	ActionPlayRoadBuilding:  "play road building",
+	ActionPlayYearOfPlenty:  "play year of plenty",
+	ActionPlayMonopoly:      "play monopoly",
+	ActionMoveRobber:        "move robber",
+	ActionBankTrade:         "bank trade",
+	ActionEndTurn:           "end turn",
+	ActionNeutralRoad:       "neutral road",
+	ActionNeutralSettlement: "neutral settlement",
+	ActionSetupShip:         "setup ship",
+	ActionBuildShip:         "build ship",
+	ActionMoveShip:          "move ship",
+	ActionForcedTrade:       "forced trade",
+	ActionGiveBack:          "give back",
+	ActionReturnRobber:      "return robber",
+}
+
+func (t ActionType) String() string {
+	if name, ok := actionNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("action %d", int(t))
+}
+
+// Action is a single move. Only the fields used by its Type are set:
+// VertexID for settlements and cities, VertexID and VertexID2 for roads and
+// ships, MoveFrom for the edge a moved ship leaves, TileID and VictimID for
+// the robber, Give and Get for trades and cards, and NeutralID for pieces
+// placed for a neutral player.
+// Actions are comparable so they can be used as map keys.
+type Action struct {
+	Type      ActionType
+	PlayerID  int
+	VertexID  int
+	VertexID2 int
+	TileID    TileID
+	VictimID  int
+	Give      string
+	Get       string
+	NeutralID int
+	MoveFrom  [2]int
+}
+
+// String describes the action with other players by number
+func (a Action) String() string {
+	return a.Describe(func(playerID int) string { return fmt.Sprintf("Player %d", playerID) })
+}
+
+// Describe says what the action does, calling each other player it involves
+// by the name given for their ID
+func (a Action) Describe(name func(playerID int) string) string {
+	switch a.Type {
+	case ActionSetupSettlement, ActionBuildSettlement, ActionBuildCity:
+		return fmt.Sprintf("%s %d", a.Type, a.VertexID)
+	case ActionSetupRoad, ActionBuildRoad, ActionSetupShip, ActionBuildShip:
+		return fmt.Sprintf("%s %d-%d", a.Type, a.VertexID, a.VertexID2)
+	case ActionMoveShip:
+		return fmt.Sprintf("%s %d-%d to %d-%d", a.Type, a.MoveFrom[0], a.MoveFrom[1], a.VertexID, a.VertexID2)
+	case ActionNeutralSettlement:
+		return fmt.Sprintf("%s %d for %s", a.Type, a.VertexID, name(a.NeutralID))
+	case ActionNeutralRoad:
+		return fmt.Sprintf("%s %d-%d for %s", a.Type, a.VertexID, a.VertexID2, name(a.NeutralID))
+	case ActionForcedTrade:
+		return fmt.Sprintf("%s with %s", a.Type, name(a.VictimID))
+	case ActionGiveBack:
+		return fmt.Sprintf("%s %s to %s", a.Type, a.Give, name(a.VictimID))
+	case ActionReturnRobber:
+		return fmt.Sprintf("%s to tile %d", a.Type, a.TileID)
+	case ActionMoveRobber:
+		if a.VictimID != 0 {
+			return fmt.Sprintf("%s to tile %d stealing from %s", a.Type, a.TileID, name(a.VictimID))
+		}
+		return fmt.Sprintf("%s to tile %d", a.Type, a.TileID)
+	case ActionBankTrade:
+		return fmt.Sprintf("%s %s for %s", a.Type, a.Give, a.Get)
+	case ActionPlayYearOfPlenty:
+		return fmt.Sprintf("%s %s %s", a.Type, a.Give, a.Get)
+	case ActionPlayMonopoly:
+		return fmt.Sprintf("%s %s", a.Type, a.Get)
+	}
+	return a.Type.String()
+}
+
+func GetPlayerByID(game *CatanGame, playerID int) *Player {
+	for _, player := range game.Players {
+		if player.ID == playerID {
+			return player
+		}
+	}
+	return nil
+}
+
+// The player whose decision the game is waiting on
+func CurrentPlayer(game *CatanGame) *Player {
+	switch game.Phase {
+	case "setup":
+		return GetPlayerByID(game, game.SetupOrder[game.SetupIndex])
+	case "special":
+		return game.Players[game.SpecialBuilder]
+	}
+	return game.Players[game.TurnIndex]
+}
+
+// Victory points including hidden Victory Point cards
+func TotalVictoryPoints(player *Player) int {
+	return player.VictoryPoints + player.DevelopmentCards["Victory Point"]
+}
+
+func handSize(player *Player) int {
+	total := 0
+	for _, count := range player.Resources {
+		total += count
+	}
+	return total
+}
+
+// BeginSetup starts the placement phase: each player places a settlement and
+// a road touching it, going round from the starting player and then back
+// again in reverse order (see GenerateSnakeOrder). The second settlement
+// collects one of each resource around it.
+func BeginSetup(game *CatanGame, startingPlayer *Player) {
+	order := GenerateSnakeOrder(game, startingPlayer)
+	startIdx := 0
+	for i, player := range game.Players {
+		if player.ID == order[0] {
+			startIdx = i
+		}
+	}
+	if len(game.Neutrals) > 0 {
+		order = setupNeutralOrder(game, order)
+	}
+
+	game.Phase = "setup"
+	game.SetupOrder = order
+	game.SetupIndex = 0
+	game.SetupVertex = 0
+	game.TurnIndex = startIdx
+}
+
+// LegalActions lists every move available to CurrentPlayer
+func LegalActions(game *CatanGame) []Action {
+	var actions []Action
+	if game.Phase == "finished" {
+		return actions
+	}
+	player := CurrentPlayer(game)
+
+	switch game.Phase {
+	case "setup":
+		if game.SetupVertex == 0 {
+			for _, vertexID := range ComputeValidVertexPlacements(game) {
+				if VertexIsland(game.Board, vertexID) == 0 { // Everyone starts on the home island
+					actions = append(actions, Action{Type: ActionSetupSettlement, PlayerID: player.ID, VertexID: vertexID})
+				}
+			}
+		} else {
+			graph := game.Board.Graph
+			for _, adjID := range ComputeValidEdgePlacements(game, game.SetupVertex) {
+				edge := graph.EdgeBetween(game.SetupVertex, adjID)
+				if landEdge(game.Board, edge) {
+					actions = append(actions, Action{Type: ActionSetupRoad, PlayerID: player.ID, VertexID: game.SetupVertex, VertexID2: adjID})
+				}
+				if seaEdge(game.Board, edge) {
+					actions = append(actions, Action{Type: ActionSetupShip, PlayerID: player.ID, VertexID: game.SetupVertex, VertexID2: adjID})
+				}
+			}
+		}
+
+	case "neutral":
+		actions = neutralActions(game, player)
+
+	case "forced":
+		actions = giveBackActions(game, player)
+
+	case "robber":
+		for _, tile := range game.Board.Tiles {
+			if tile.ID == game.Board.RobberPosition || tile.Resource == Sea {
+				continue
+			}
+			victims := robberVictims(game, player, tile.ID)
+			if len(victims) == 0 {
+				actions = append(actions, Action{Type: ActionMoveRobber, PlayerID: player.ID, TileID: tile.ID})
+			}
+			for _, victimID := range victims {
+				actions = append(actions, Action{Type: ActionMoveRobber, PlayerID: player.ID, TileID: tile.ID, VictimID: victimID})
+			}
+		}
+
+	case "main":
+		if !game.HasRolled {
+			actions = append(actions, Action{Type: ActionRoll, PlayerID: player.ID})
+			if canPlayDevCard(game, player, "Knight") {
+				actions = append(actions, Action{Type: ActionPlayKnight, PlayerID: player.ID})
+			}
+			return actions
+		}
+
+		actions = appendBuildActions(game, player, actions)
+		actions = append(actions, shipMoveActions(game, player)...)
+		actions = append(actions, tradeTokenActions(game, player)...)
+
+		if canPlayDevCard(game, player, "Knight") {
+			actions = append(actions, Action{Type: ActionPlayKnight, PlayerID: player.ID})
+		}
+		if canPlayDevCard(game, player, "Road Building") && len(ComputeValidRoadPlacements(game, player)) > 0 {
+			actions = append(actions, Action{Type: ActionPlayRoadBuilding, PlayerID: player.ID})
+		}
+		if canPlayDevCard(game, player, "Year of Plenty") {
+			for i, first := range ResourceTypes {
+				for _, second := range ResourceTypes[i:] {
+					if bankCanPay(game, first, second) {
+						actions = append(actions, Action{Type: ActionPlayYearOfPlenty, PlayerID: player.ID, Give: first, Get: second})
+					}
+				}
+			}
+		}
+		if canPlayDevCard(game, player, "Monopoly") {
+			for _, resource := range ResourceTypes {
+				actions = append(actions, Action{Type: ActionPlayMonopoly, PlayerID: player.ID, Get: resource})
+			}
+		}
+
+		for _, give := range ResourceTypes {
+			if player.Resources[give] < TradeRatio(game, player, give) {
+				continue
+			}
+			for _, get := range ResourceTypes {
+				if get != give && game.Bank.Resources[get] > 0 {
+					actions = append(actions, Action{Type: ActionBankTrade, PlayerID: player.ID, Give: give, Get: get})
+				}
+			}
+		}
+
+		actions = append(actions, Action{Type: ActionEndTurn, PlayerID: player.ID})
+
+	case "special":
+		// Building and buying only, ending the turn passes to the next builder
+		actions = appendBuildActions(game, player, actions)
+		actions = append(actions, Action{Type: ActionEndTurn, PlayerID: player.ID})
+	}
+
+	return actions
+}
+
+// Roads, ships, settlements, cities and development cards the player can pay for
+func appendBuildActions(game *CatanGame, player *Player, actions []Action) []Action {
+	if game.FreeRoads > 0 || CanPlayerAfford(player, "road") {
+		for _, road := range ComputeValidRoadPlacements(game, player) {
+			actions = append(actions, Action{Type: ActionBuildRoad, PlayerID: player.ID, VertexID: road[0], VertexID2: road[1]})
+		}
+	}
+	if CanPlayerAfford(player, "ship") {
+		for _, ship := range ComputeValidShipPlacements(game, player) {
+			actions = append(actions, Action{Type: ActionBuildShip, PlayerID: player.ID, VertexID: ship[0], VertexID2: ship[1]})
+		}
+	}
+	if CanPlayerAfford(player, "settlement") {
+		for _, vertexID := range ComputeValidSettlementPlacements(game, player) {
+			actions = append(actions, Action{Type: ActionBuildSettlement, PlayerID: player.ID, VertexID: vertexID})
+		}
+	}
+	if CanPlayerAfford(player, "city") {
+		for _, vertexID := range ComputeValidCityPlacements(game, player) {
+			actions = append(actions, Action{Type: ActionBuildCity, PlayerID: player.ID, VertexID: vertexID})
+		}
+	}
+	if CanPlayerAfford(player, "dev") && len(game.Bank.DevelopmentCards) > 0 {
+		actions = append(actions, Action{Type: ActionBuyDevCard, PlayerID: player.ID})
+	}
+	return actions
+}
+
+// ApplyAction carries out a move for CurrentPlayer. Dice rolls, card draws
+// and robber steals take their randomness from rng. An error is returned,
+// and the game left untouched, if the move is not legal right now.
+func ApplyAction(game *CatanGame, action Action, rng *rand.Rand) error {
+	if !isLegal(game, action) {
+		return fmt.Errorf("%s is not a legal move right now", describeAction(game, action))
+	}
+	applyAction(game, action, rng)
+	return nil
+}
+
+// applyAction is ApplyAction without the legality check, for callers that
+// picked the action from LegalActions themselves
+func applyAction(game *CatanGame, action Action, rng *rand.Rand) {
+	player := CurrentPlayer(game)
+
+	switch action.Type {
+	case ActionSetupSettlement:
+		owner := setupOwner(game)
+		PlaceSettlement(action.VertexID, owner, game)
+		if !owner.Neutral && setupPlacements(game, owner) == 2 {
+			// Second settlement: collect one of each surrounding resource
+			for _, tile := range vertexTiles(game, action.VertexID) {
+				if tile.Produces() {
+					BankToPlayerResource(game, player, tile.Resource, 1)
+				}
+			}
+		}
+		game.SetupVertex = action.VertexID
+
+	case ActionSetupRoad, ActionSetupShip:
+		if action.Type == ActionSetupShip {
+			PlaceShip(action.VertexID, action.VertexID2, setupOwner(game), game)
+		} else {
+			PlaceRoad(action.VertexID, action.VertexID2, setupOwner(game), game)
+		}
+		game.SetupVertex = 0
+		game.SetupIndex++
+		if game.SetupIndex == len(game.SetupOrder) {
+			game.Phase = "main"
+			game.SetupIndex = 0
+		}
+
+	case ActionRoll:
+		game.HasRolled = true
+		game.LastRoll = rng.Intn(6) + rng.Intn(6) + 2
+		if len(game.Neutrals) > 0 {
+			// The 2-player variant rolls twice, the second time until it differs
+			for game.FirstRoll != 0 && game.LastRoll == game.FirstRoll {
+				game.LastRoll = rng.Intn(6) + rng.Intn(6) + 2
+			}
+			if game.FirstRoll == 0 {
+				game.FirstRoll = game.LastRoll
+				game.HasRolled = false
+			}
+		}
+		if game.LastRoll == 7 {
+			discardHalf(game)
+			game.Phase = "robber"
+		} else {
+			ProduceResources(game, game.LastRoll)
+		}
+
+	case ActionBuildRoad:
+		ValidateAndPlaceRoad(action.VertexID, action.VertexID2, player, game)
+		updateLongestRoad(game)
+		oweNeutralBuild(game, "road")
+
+	case ActionBuildShip:
+		ValidateAndPlaceShip(action.VertexID, action.VertexID2, player, game)
+		updateLongestRoad(game)
+
+	case ActionMoveShip:
+		moveShip(game, player, action.MoveFrom, action.VertexID, action.VertexID2)
+		updateLongestRoad(game)
+
+	case ActionBuildSettlement:
+		ValidateAndPlaceSettlement(action.VertexID, player, game)
+		updateLongestRoad(game) // A new settlement can cut an opponent's road
+		earnTradeTokens(game, player, action.VertexID, "settlement")
+		oweNeutralBuild(game, "settlement")
+
+	case ActionNeutralRoad:
+		PlaceRoad(action.VertexID, action.VertexID2, GetNeutralByID(game, action.NeutralID), game)
+		game.NeutralBuild = ""
+		game.Phase = "main"
+
+	case ActionNeutralSettlement:
+		PlaceSettlement(action.VertexID, GetNeutralByID(game, action.NeutralID), game)
+		updateLongestRoad(game)
+		game.NeutralBuild = ""
+		game.Phase = "main"
+
+	case ActionBuildCity:
+		payCost(game, player, "city")
+		PlaceCity(action.VertexID, player, game)
+		earnTradeTokens(game, player, action.VertexID, "city")
+
+	case ActionBuyDevCard:
+		payCost(game, player, "dev")
+		card := game.Bank.DevelopmentCards[0]
+		game.Bank.DevelopmentCards = game.Bank.DevelopmentCards[1:]
+		player.DevelopmentCards[card.Type]++
+		if game.Phase != "special" {
+			// Bought between turns, so it can be played on the buyer's next turn
+			player.BoughtThisTurn[card.Type]++
+		}
+
+	case ActionPlayKnight:
+		useDevCard(game, player, "Knight")
+		player.KnightsPlayed++
+		updateLargestArmy(game, player)
+		earnTradeTokens(game, player, 0, "knight")
+		game.Phase = "robber"
+
+	case ActionPlayRoadBuilding:
+		useDevCard(game, player, "Road Building")
+		game.FreeRoads = 2
+
+	case ActionPlayYearOfPlenty:
+		useDevCard(game, player, "Year of Plenty")
+		BankToPlayerResource(game, player, action.Give, 1)
+		BankToPlayerResource(game, player, action.Get, 1)
+
+	case ActionPlayMonopoly:
+		useDevCard(game, player, "Monopoly")
+		for _, other := range game.Players {
+			if other != player {
+				player.Resources[action.Get] += other.Resources[action.Get]
+				other.Resources[action.Get] = 0
+			}
+		}
+
+	case ActionMoveRobber:
+		game.Board.RobberPosition = action.TileID
+		if action.VictimID != 0 {
+			stealRandomResource(GetPlayerByID(game, action.VictimID), player, rng)
+		}
+		game.Phase = "main"
+
+	case ActionForcedTrade:
+		forcedTrade(game, player, rng)
+
+	case ActionGiveBack:
+		giveBack(game, player, action.Give)
+
+	case ActionReturnRobber:
+		player.TradeTokens -= tradeTokenCost(game, player)
+		game.Board.RobberPosition = action.TileID
+
+	case ActionBankTrade:
+		PlayerToBankResource(game, player, action.Give, TradeRatio(game, player, action.Give))
+		BankToPlayerResource(game, player, action.Get, 1)
+
+	case ActionEndTurn:
+		if game.Phase == "special" {
+			nextSpecialBuilder(game)
+			return
+		}
+		endTurn(game)
+		if game.SpecialBuild {
+			// Everyone but the player who just finished may build, starting
+			// with the player whose turn is next
+			game.Phase = "special"
+			game.SpecialBuilder = game.TurnIndex
+		}
+		return
+	}
+
+	// Players can only win on their own turn
+	if game.Phase != "setup" && game.Phase != "special" && TotalVictoryPoints(player) >= VictoryPointsToWin {
+		game.WinnerID = player.ID
+		game.Phase = "finished"
+	}
+}
+
+// Which of the player's own setup settlements is being placed now, 1 or 2.
+// Placements made for neutral players come after the snake and do not count.
+func setupPlacements(game *CatanGame, player *Player) int {
+	count := 0
+	for _, id := range game.SetupOrder[:min(game.SetupIndex+1, 2*len(game.Players))] {
+		if id == player.ID {
+			count++
+		}
+	}
+	return count
+}
+
+func isLegal(game *CatanGame, action Action) bool {
+	for _, legal := range LegalActions(game) {
+		if legal == action {
+			return true
+		}
+	}
+	return false
+}
+
+func endTurn(game *CatanGame) {
+	player := game.Players[game.TurnIndex]
+	player.BoughtThisTurn = make(map[string]int)
+	game.HasRolled = false
+	game.DevCardPlayed = false
+	game.FreeRoads = 0
+	game.FirstRoll = 0
+	game.ForcedTrade = false
+	game.ShipMoved = false
+	game.NewShips = nil
+	game.TurnIndex = (game.TurnIndex + 1) % len(game.Players)
+	game.TurnCount++
+}
+
+// Passes the special building phase on, back to the main phase once the
+// player before TurnIndex has had their go
+func nextSpecialBuilder(game *CatanGame) {
+	n := len(game.Players)
+	if game.SpecialBuilder == (game.TurnIndex+n-2)%n {
+		game.Phase = "main"
+		return
+	}
+	game.SpecialBuilder = (game.SpecialBuilder + 1) % n
+}
+
+// Tiles touching a vertex
+func vertexTiles(game *CatanGame, vertexID int) []*Tile {
+	var tiles []*Tile
+	for _, tileID := range game.Board.Graph.layout.vertices[vertexID].TileIds {
+		if tile := GetTileByID(game, tileID); tile != nil {
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles
+}
+
+// ProduceResources pays every building on a tile with the rolled number,
+// skipping the robber's tile. If the bank cannot pay everyone owed a
+// resource then nobody receives it, unless only one player is owed it, who
+// gets whatever the bank has left.
+func ProduceResources(game *CatanGame, roll int) {
+	owed := make(map[int]map[string]int) // player ID -> resource -> amount
+	totals := make(map[string]int)
+	recipients := make(map[string]int) // Players owed each resource
+
+	for vertexID := 1; vertexID <= game.Board.Graph.VertexCount(); vertexID++ {
+		owner := VertexOwner(game, vertexID)
+		if owner == nil || owner.Neutral {
+			continue
+		}
+		building := VertexBuilding(game, vertexID)
+		for _, tile := range vertexTiles(game, vertexID) {
+			if tile.NumberToken != roll || tile.ID == game.Board.RobberPosition || tile.Resource == "D" {
+				continue
+			}
+			if owed[owner.ID] == nil {
+				owed[owner.ID] = make(map[string]int)
+			}
+			if owed[owner.ID][tile.Resource] == 0 {
+				recipients[tile.Resource]++
+			}
+			owed[owner.ID][tile.Resource] += building
+			totals[tile.Resource] += building
+		}
+	}
+
+	for playerID, resources := range owed {
+		for resource, amount := range resources {
+			switch {
+			case totals[resource] <= game.Bank.Resources[resource]:
+				BankToPlayerResource(game, GetPlayerByID(game, playerID), resource, amount)
+			case recipients[resource] == 1 && game.Bank.Resources[resource] > 0:
+				BankToPlayerResource(game, GetPlayerByID(game, playerID), resource, game.Bank.Resources[resource])
+			}
+		}
+	}
+}
+
+// On a seven everyone holding more than seven cards discards half of them,
+// giving up whatever they hold most of first
+func discardHalf(game *CatanGame) {
+	for _, player := range game.Players {
+		toDiscard := handSize(player) / 2
+		if handSize(player) <= 7 {
+			continue
+		}
+		for ; toDiscard > 0; toDiscard-- {
+			most := ResourceTypes[0]
+			for _, resource := range ResourceTypes {
+				if player.Resources[resource] > player.Resources[most] {
+					most = resource
+				}
+			}
+			PlayerToBankResource(game, player, most, 1)
+		}
+	}
+}
+
+// Players other than thief with a building on the tile and cards to steal
+func robberVictims(game *CatanGame, thief *Player, tileID TileID) []int {
+	seen := make(map[int]bool)
+	var victims []int
+	for vertexID := 1; vertexID <= game.Board.Graph.VertexCount(); vertexID++ {
+		owner := VertexOwner(game, vertexID)
+		if owner == nil || owner == thief || seen[owner.ID] {
+			continue
+		}
+		for _, tile := range vertexTiles(game, vertexID) {
+			if tile.ID == tileID && handSize(owner) > 0 {
+				seen[owner.ID] = true
+				victims = append(victims, owner.ID)
+				break
+			}
+		}
+	}
+	sort.Ints(victims)
+	return victims
+}
+
+func stealRandomResource(victim, thief *Player, rng *rand.Rand) {
+	pick := rng.Intn(handSize(victim))
+	for _, resource := range ResourceTypes {
+		if pick < victim.Resources[resource] {
+			victim.Resources[resource]--
+			thief.Resources[resource]++
+			return
+		}
+		pick -= victim.Resources[resource]
+	}
+}
+
+// Only one development card per turn, and not one bought this turn.
+// Victory Point cards are never played, they simply count.
+func canPlayDevCard(game *CatanGame, player *Player, cardType string) bool {
+	return !game.DevCardPlayed && player.DevelopmentCards[cardType]-player.BoughtThisTurn[cardType] > 0
+}
+
+func useDevCard(game *CatanGame, player *Player, cardType string) {
+	player.DevelopmentCards[cardType]--
+	game.DevCardPlayed = true
+}
+
+func bankCanPay(game *CatanGame, first, second string) bool {
+	if first == second {
+		return game.Bank.Resources[first] >= 2
+	}
+	return game.Bank.Resources[first] >= 1 && game.Bank.Resources[second] >= 1
+}
+
+// TradeWithPlayer swaps cards between the player whose turn it is and a
+// partner who has agreed to it. Both must hold what they give, and trades are
+// only made on the main part of a turn after the roll.
+func TradeWithPlayer(game *CatanGame, partner *Player, give, get map[string]int) error {
+	player := CurrentPlayer(game)
+	if game.Phase != "main" || !game.HasRolled {
+		return errors.New("trades can only be made after rolling")
+	}
+	if partner == nil || partner == player || partner.Neutral {
+		return errors.New("trades are made with another player in the game")
+	}
+	if len(give) == 0 || len(get) == 0 {
+		return errors.New("a trade needs cards going both ways")
+	}
+	for resource, amount := range give {
+		if get[resource] > 0 {
+			return fmt.Errorf("%s cannot be on both sides of a trade", resource)
+		}
+		if amount < 1 || player.Resources[resource] < amount {
+			return fmt.Errorf("%s does not have %d %s", player, amount, resource)
+		}
+	}
+	for resource, amount := range get {
+		if amount < 1 || partner.Resources[resource] < amount {
+			return fmt.Errorf("%s does not have %d %s", partner, amount, resource)
+		}
+	}
+	for resource, amount := range give {
+		player.Resources[resource] -= amount
+		partner.Resources[resource] += amount
+	}
+	for resource, amount := range get {
+		partner.Resources[resource] -= amount
+		player.Resources[resource] += amount
+	}
+	return nil
+}
+
+// TradeRatio is how many of a resource the player gives the bank for one card:
+// 4 by default, 3 with a generic port, 2 with that resource's port
+func TradeRatio(game *CatanGame, player *Player, resource string) int {
+	ratio := 4
+	for _, port := range game.Board.Ports {
+		owned := false
+		for _, vertexID := range port.VertexIDs {
+			if vertexID <= game.Board.Graph.VertexCount() && VertexOwner(game, vertexID) == player {
+				owned = true
+			}
+		}
+		if !owned {
+			continue
+		}
+		if port.GiveResource == resource {
+			return 2
+		}
+		if port.GiveResource == "A" {
+			ratio = 3
+		}
+	}
+	return ratio
+}
+
+// LongestRoadLength is the longest chain of the player's roads and ships that
+// does not pass through an opponent's building. The chain only changes
+// between roads and ships at one of the player's own buildings.
+func LongestRoadLength(game *CatanGame, player *Player) int {
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	used := make([]bool, graph.EdgeCount())
+	piece := func(edge int) uint8 { // 1 for the player's road, 2 for their ship
+		switch {
+		case slot == 0:
+			return 0
+		case graph.edgeOwners[edge] == slot:
+			return 1
+		case graph.shipOwners[edge] == slot:
+			return 2
+		}
+		return 0
+	}
+
+	var walk func(vertexID int, last uint8) int
+	walk = func(vertexID int, last uint8) int {
+		owner := graph.vertexOwners[vertexID]
+		if last != 0 && owner != 0 && owner != slot {
+			return 0 // Opponent's building cuts the road here
+		}
+		best := 0
+		for _, edge := range graph.layout.vertexEdges[vertexID] {
+			kind := piece(edge)
+			if used[edge] || kind == 0 || (last != 0 && kind != last && owner != slot) {
+				continue
+			}
+			used[edge] = true
+			next, other := graph.EdgeVertices(edge)
+			if next == vertexID {
+				next = other
+			}
+			if length := 1 + walk(next, kind); length > best {
+				best = length
+			}
+			used[edge] = false
+		}
+		return best
+	}
+
+	longest := 0
+	for edge := 0; edge < graph.EdgeCount(); edge++ {
+		if piece(edge) == 0 {
+			continue
+		}
+		a, b := graph.EdgeVertices(edge)
+		for _, start := range [2]int{a, b} {
+			if length := walk(start, 0); length > longest {
+				longest = length
+			}
+		}
+	}
+	return longest
+}
+
+// Recomputes every road and moves the longest road bonus if needed. The holder
+// keeps it on a tie; if they lose it and several players tie, nobody holds it.
+func updateLongestRoad(game *CatanGame) {
+	best := 0
+	for _, player := range game.Players {
+		player.LongestRoad = LongestRoadLength(game, player)
+		if player.LongestRoad > best {
+			best = player.LongestRoad
+		}
+	}
+
+	holder := GetPlayerByID(game, game.LongestRoadID)
+	if holder != nil && holder.LongestRoad == best && best >= 5 {
+		return
+	}
+
+	var leaders []*Player
+	for _, player := range game.Players {
+		if player.LongestRoad == best && best >= 5 {
+			leaders = append(leaders, player)
+		}
+	}
+
+	if holder != nil {
+		holder.VictoryPoints -= 2
+		game.LongestRoadID = 0
+	}
+	if len(leaders) == 1 {
+		leaders[0].VictoryPoints += 2
+		game.LongestRoadID = leaders[0].ID
+	}
+}
+
+func updateLargestArmy(game *CatanGame, player *Player) {
+	if player.KnightsPlayed < 3 || game.LargestArmyID == player.ID {
+		return
+	}
+	holder := GetPlayerByID(game, game.LargestArmyID)
+	if holder != nil {
+		if holder.KnightsPlayed >= player.KnightsPlayed {
+			return
+		}
+		holder.VictoryPoints -= 2
+	}
+	player.VictoryPoints += 2
+	game.LargestArmyID = player.ID
+}
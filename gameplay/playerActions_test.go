@@ -0,0 +1,215 @@
+package gameplay
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// A source whose first roll of two dice totals the number given
+func rollingSource(t *testing.T, total int) *rand.Rand {
+	t.Helper()
+	for seed := int64(1); seed < 1000; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		if rng.Intn(6)+rng.Intn(6)+2 == total {
+			return rand.New(rand.NewSource(seed))
+		}
+	}
+	t.Fatalf("no seed rolls %d", total)
+	return nil
+}
+
+func onlyTypes(t *testing.T, phase string, legal []Action, types ...ActionType) {
+	t.Helper()
+	if len(legal) == 0 {
+		t.Fatalf("no legal moves in the %s phase", phase)
+	}
+	for _, action := range legal {
+		allowed := false
+		for _, actionType := range types {
+			allowed = allowed || action.Type == actionType
+		}
+		if !allowed {
+			t.Errorf("%s is legal in the %s phase", action, phase)
+		}
+	}
+}
+
+func TestLegalActionsPerPhase(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	BeginSetup(game, game.Players[0])
+	onlyTypes(t, "setup", LegalActions(game), ActionSetupSettlement)
+	settlement := LegalActions(game)[0]
+	applyAction(game, settlement, nil)
+	onlyTypes(t, "setup", LegalActions(game), ActionSetupRoad)
+	for _, road := range LegalActions(game) {
+		if road.VertexID != settlement.VertexID {
+			t.Errorf("%s does not touch the settlement on %d", road, settlement.VertexID)
+		}
+	}
+
+	game = setUpGame(t)
+	onlyTypes(t, "main", LegalActions(game), ActionRoll)
+	before := game.Clone()
+	if err := ApplyAction(game, Action{Type: ActionEndTurn, PlayerID: 1}, nil); err == nil {
+		t.Error("the turn ended before the roll")
+	}
+	if !game.Equal(before) {
+		t.Error("an illegal move changed the game")
+	}
+
+	applyAction(game, Action{Type: ActionRoll, PlayerID: 1}, rollingSource(t, 7))
+	onlyTypes(t, "robber", LegalActions(game), ActionMoveRobber)
+	for _, action := range LegalActions(game) {
+		if action.TileID == game.Board.RobberPosition {
+			t.Errorf("%s leaves the robber where it is", action)
+		}
+	}
+	applyAction(game, LegalActions(game)[0], rand.New(rand.NewSource(1)))
+	if legal := LegalActions(game); containsActionType(legal, ActionRoll) || !containsActionType(legal, ActionEndTurn) {
+		t.Errorf("after the robber moved the legal moves are %v, expected to end the turn and not roll", legal)
+	}
+
+	game.Phase = "finished"
+	if legal := LegalActions(game); len(legal) != 0 {
+		t.Errorf("%v are legal after the game finished", legal)
+	}
+}
+
+// No settlement next to another, and roads only off the player's own network
+func TestDistanceAndRoadRules(t *testing.T) {
+	game := setUpGame(t)
+	player := game.Players[0]
+	for _, vertexID := range ComputeValidVertexPlacements(game) {
+		if VertexOwner(game, vertexID) != nil {
+			t.Errorf("vertex %d is taken but open to settle", vertexID)
+		}
+		for _, adjID := range GetVertexByID(game, vertexID).AdjacentVertexes {
+			if VertexOwner(game, adjID) != nil {
+				t.Errorf("vertex %d is next to a building on %d but open to settle", vertexID, adjID)
+			}
+		}
+	}
+
+	reaches := func(vertexID int) bool {
+		if owner := VertexOwner(game, vertexID); owner != nil {
+			return owner == player
+		}
+		for _, adjID := range GetVertexByID(game, vertexID).AdjacentVertexes {
+			if RoadOwner(game, vertexID, adjID) == player {
+				return true
+			}
+		}
+		return false
+	}
+	roads := ComputeValidRoadPlacements(game, player)
+	if len(roads) == 0 {
+		t.Fatal("player 1 has nowhere to build a road")
+	}
+	for _, road := range roads {
+		if RoadOwner(game, road[0], road[1]) != nil {
+			t.Errorf("road %d-%d is built on", road[0], road[1])
+		}
+		if !reaches(road[0]) && !reaches(road[1]) {
+			t.Errorf("road %d-%d is not connected to player 1's", road[0], road[1])
+		}
+	}
+	for _, vertexID := range ComputeValidSettlementPlacements(game, player) {
+		if !reaches(vertexID) {
+			t.Errorf("vertex %d is open to settle without a road to it", vertexID)
+		}
+	}
+
+	player.Resources = map[string]int{"B": 1, "L": 1}
+	game.HasRolled = true
+	far := Action{Type: ActionBuildRoad, PlayerID: player.ID}
+	for edge := 0; edge < game.Board.Graph.EdgeCount() && far.VertexID == 0; edge++ {
+		a, b := game.Board.Graph.EdgeVertices(edge)
+		if RoadOwner(game, a, b) == nil && !reaches(a) && !reaches(b) {
+			far.VertexID, far.VertexID2 = min(a, b), max(a, b)
+		}
+	}
+	if far.VertexID == 0 {
+		t.Fatal("every edge is next to player 1's network")
+	}
+	if err := ApplyAction(game, far, nil); err == nil {
+		t.Errorf("%s was built away from player 1's roads", far)
+	}
+}
+
+// A seven makes everyone over seven cards discard half, then the robber
+// moves and steals a card
+func TestRobberAndDiscard(t *testing.T) {
+	game := setUpGame(t)
+	thief, victim := game.Players[0], game.Players[1]
+	thief.Resources = map[string]int{"B": 3, "L": 3, "O": 3}
+	victim.Resources = map[string]int{"W": 7}
+
+	applyAction(game, Action{Type: ActionRoll, PlayerID: thief.ID}, rollingSource(t, 7))
+	if handSize(thief) != 5 || handSize(victim) != 7 {
+		t.Errorf("hands of %d and %d after the seven, expected 5 and 7", handSize(thief), handSize(victim))
+	}
+	if game.Phase != "robber" {
+		t.Fatalf("the phase is %s after a seven", game.Phase)
+	}
+
+	var steal Action
+	for _, action := range LegalActions(game) {
+		if action.VictimID == victim.ID {
+			steal = action
+		}
+	}
+	if steal.VictimID == 0 {
+		t.Fatal("player 2 cannot be robbed")
+	}
+	if err := ApplyAction(game, steal, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	if game.Board.RobberPosition != steal.TileID || game.Phase != "main" {
+		t.Errorf("the robber is on %d in the %s phase, expected %d in main", game.Board.RobberPosition, game.Phase, steal.TileID)
+	}
+	if thief.Resources["W"] != 1 || handSize(victim) != 6 {
+		t.Errorf("player 1 has %v and player 2 %v after the steal", thief.Resources, victim.Resources)
+	}
+}
+
+// When the bank runs short of a resource only one player is owed, they get
+// what is left. With more players owed it, nobody gets any.
+func TestProduceResourcesBankShortage(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2}, 1)
+	var tile *Tile
+	for _, candidate := range game.Board.Tiles {
+		if candidate.Produces() && candidate.ID != game.Board.RobberPosition {
+			tile = candidate
+			break
+		}
+	}
+	var corners []int
+	for vertexID := 1; vertexID <= game.Board.Graph.VertexCount(); vertexID++ {
+		for _, around := range vertexTiles(game, vertexID) {
+			if around == tile {
+				corners = append(corners, vertexID)
+			}
+		}
+	}
+	one, two := game.Players[0], game.Players[1]
+	resource := tile.Resource
+	setBuilding(game, corners[0], one, 2)
+	game.Bank.Resources[resource] = 1
+	ProduceResources(game, tile.NumberToken)
+	if one.Resources[resource] != 1 || game.Bank.Resources[resource] != 0 {
+		t.Errorf("the only player owed %s got %d with %d left in the bank, expected the last one", resource, one.Resources[resource], game.Bank.Resources[resource])
+	}
+
+	for _, vertexID := range corners {
+		if !containsVertex(game.Board.Graph.Neighbors(corners[0]), vertexID) && vertexID != corners[0] {
+			setBuilding(game, vertexID, two, 1)
+			break
+		}
+	}
+	one.Resources[resource] = 0
+	game.Bank.Resources[resource] = 1
+	ProduceResources(game, tile.NumberToken)
+	if one.Resources[resource] != 0 || two.Resources[resource] != 0 || game.Bank.Resources[resource] != 1 {
+		t.Errorf("the bank paid out its last %s to one of two players owed it", resource)
+	}
+}
@@ -0,0 +1,160 @@
+// ports.go
+
+// Ports sit on coastal edges: edges along the side of exactly one land tile,
+// with the sea or the edge of the board on the other side. A port knows which
+// tile it is on and which way it faces so it can be drawn pointing out to sea.
+package gameplay
+
+import "fmt"
+
+// Sides of a tile between corners i and i+1 (see HexCoord.corners) face the
+// direction hexDirections[sideFacing[i]]
+var sideFacing = [6]int{5, 0, 1, 2, 3, 4}
+
+// CoastalSide finds the tile a coastal edge belongs to and the direction, as
+// an index into hexDirections, from that tile out to sea. ok is false if the
+// vertices are not the ends of a coastal edge.
+func CoastalSide(board *Board, vertexID1, vertexID2 int) (TileID, int, bool) {
+	graph := board.Graph
+	if graph.EdgeBetween(vertexID1, vertexID2) < 0 {
+		return 0, 0, false
+	}
+	var land []TileID
+	for _, tileID := range sharedTiles(graph, vertexID1, vertexID2) {
+		if board.Tiles[tileID-1].Resource != Sea {
+			land = append(land, tileID)
+		}
+	}
+	if len(land) != 1 {
+		return 0, 0, false
+	}
+
+	corners := board.Topology.Tiles[land[0]-1].corners()
+	ends := map[VertexPos]bool{
+		board.Topology.Vertices[vertexID1-1]: true,
+		board.Topology.Vertices[vertexID2-1]: true,
+	}
+	for i := range corners {
+		if ends[corners[i]] && ends[corners[(i+1)%6]] {
+			return land[0], sideFacing[i], true
+		}
+	}
+	return 0, 0, false
+}
+
+// Puts a port of the given kind on the edge between two vertices, checking
+// the edge is on the coast and free of other ports
+func addPort(board *Board, kind string, vertexIDs [2]int) error {
+	tileID, facing, ok := CoastalSide(board, vertexIDs[0], vertexIDs[1])
+	if !ok {
+		if board.Graph.EdgeBetween(vertexIDs[0], vertexIDs[1]) < 0 {
+			return fmt.Errorf("vertices %d and %d are not joined by an edge", vertexIDs[0], vertexIDs[1])
+		}
+		return fmt.Errorf("edge %s is not on the coast", EdgeKey(vertexIDs[0], vertexIDs[1]))
+	}
+	for i, port := range board.Ports {
+		for _, v := range vertexIDs {
+			if port.VertexIDs[0] == v || port.VertexIDs[1] == v {
+				return fmt.Errorf("vertex %d already has port %d", v, i+1)
+			}
+		}
+	}
+	board.Ports = append(board.Ports, Port{GiveResource: kind, VertexIDs: vertexIDs, Tile: tileID, Facing: facing})
+	return nil
+}
+
+// ValidatePorts checks every port is on its own coastal edge and faces the sea
+func ValidatePorts(board *Board) error {
+	for i, port := range board.Ports {
+		tileID, facing, ok := CoastalSide(board, port.VertexIDs[0], port.VertexIDs[1])
+		if !ok {
+			return fmt.Errorf("port %d on %s is not on a coastal edge", i+1, EdgeKey(port.VertexIDs[0], port.VertexIDs[1]))
+		}
+		if tileID != port.Tile || facing != port.Facing {
+			return fmt.Errorf("port %d on %s should face direction %d from tile %d", i+1, EdgeKey(port.VertexIDs[0], port.VertexIDs[1]), facing, tileID)
+		}
+		for j, other := range board.Ports[:i] {
+			for _, v := range port.VertexIDs {
+				if other.VertexIDs[0] == v || other.VertexIDs[1] == v {
+					return fmt.Errorf("ports %d and %d share vertex %d", j+1, i+1, v)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CoastalEdges lists the edges bordering only one tile, in order around the
+// coast starting from the lowest numbered edge
+func CoastalEdges(graph *Graph) [][2]int {
+	var coast [][2]int
+	for edge := 0; edge < graph.EdgeCount(); edge++ {
+		a, b := graph.EdgeVertices(edge)
+		if len(sharedTiles(graph, a, b)) == 1 {
+			coast = append(coast, [2]int{a, b})
+		}
+	}
+	return coastLoop(coast)
+}
+
+// HomeCoast lists the coastal edges of the home island in order around it,
+// which on a board without sea is the whole coast
+func HomeCoast(board *Board) [][2]int {
+	if !HasSea(board) {
+		return CoastalEdges(board.Graph)
+	}
+	var coast [][2]int
+	for edge := 0; edge < board.Graph.EdgeCount(); edge++ {
+		a, b := board.Graph.EdgeVertices(edge)
+		if tileID, _, ok := CoastalSide(board, a, b); ok && board.terrain.tileIslands[tileID-1] == 0 {
+			coast = append(coast, [2]int{a, b})
+		}
+	}
+	return coastLoop(coast)
+}
+
+// Orders edges into a walk from the first, each sharing a vertex with the one
+// before it
+func coastLoop(coast [][2]int) [][2]int {
+	if len(coast) == 0 {
+		return coast
+	}
+	byVertex := make(map[int][]int) // vertex -> indexes into coast
+	for i, edge := range coast {
+		byVertex[edge[0]] = append(byVertex[edge[0]], i)
+		byVertex[edge[1]] = append(byVertex[edge[1]], i)
+	}
+
+	ordered := [][2]int{coast[0]}
+	used := map[int]bool{0: true}
+	vertex := coast[0][1]
+	for len(ordered) < len(coast) {
+		next := -1
+		for _, index := range byVertex[vertex] {
+			if !used[index] {
+				next = index
+			}
+		}
+		if next < 0 {
+			break // Coast is not a single loop
+		}
+		used[next] = true
+		ordered = append(ordered, coast[next])
+		if coast[next][0] == vertex {
+			vertex = coast[next][1]
+		} else {
+			vertex = coast[next][0]
+		}
+	}
+	return ordered
+}
+
+// Spreads count ports as evenly as the home island's coast allows
+func evenPortPositions(board *Board, count int) [][2]int {
+	coast := HomeCoast(board)
+	positions := make([][2]int, 0, count)
+	for i := 0; i < count; i++ {
+		positions = append(positions, coast[i*len(coast)/count])
+	}
+	return positions
+}
@@ -0,0 +1,64 @@
+package gameplay
+
+import "testing"
+
+// The base board's ports are the official frame: walking the coast from the
+// north-west side of tile 1 they are 2, 3, 2, 2, 3, 2, 2, 3 and 2 edges apart
+func TestBasePortPositions(t *testing.T) {
+	_, graph := GenerateTopology(BaseBoard.Shape)
+	coast := CoastalEdges(graph)
+	if len(coast) != 30 {
+		t.Fatalf("base board has %d coastal edges, expected 30", len(coast))
+	}
+	next := 0
+	for i, gap := range []int{2, 3, 2, 2, 3, 2, 2, 3, 2} {
+		if BaseBoard.PortPositions[i] != coast[next] {
+			t.Errorf("port %d is on %v, expected %v", i+1, BaseBoard.PortPositions[i], coast[next])
+		}
+		next += 1 + gap
+	}
+}
+
+func TestPortsFaceTheSea(t *testing.T) {
+	for _, spec := range []BoardSpec{BaseBoard, ExtensionBoard} {
+		game, err := NewCatanGameOnBoard([]int{1, 2, 3}, spec, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		board := game.Board
+		if err := ValidatePorts(board); err != nil {
+			t.Errorf("%s board: %v", spec.Name, err)
+		}
+		onBoard := make(map[HexCoord]bool)
+		for _, tile := range board.Tiles {
+			onBoard[tile.Coord] = true
+		}
+		for i, port := range board.Ports {
+			tile := board.Tiles[port.Tile-1]
+			if onBoard[tile.Coord.Neighbor(port.Facing)] {
+				t.Errorf("%s board port %d faces tile %v instead of the sea", spec.Name, i+1, tile.Coord.Neighbor(port.Facing))
+			}
+			if !containsTile(board.Graph.layout.vertices[port.VertexIDs[0]].TileIds, port.Tile) {
+				t.Errorf("%s board port %d is not on tile %d", spec.Name, i+1, port.Tile)
+			}
+		}
+	}
+
+	board := NewSeededCatanGame([]int{1, 2, 3}, 1).Board
+	board.Ports[0].Facing = (board.Ports[0].Facing + 1) % 6
+	if ValidatePorts(board) == nil {
+		t.Error("expected an error for a port facing the wrong way")
+	}
+	if err := addPort(board, "A", [2]int{11, 12}); err == nil {
+		t.Error("expected an error for a port inland")
+	}
+}
+
+func containsTile(tileIDs []TileID, tileID TileID) bool {
+	for _, id := range tileIDs {
+		if id == tileID {
+			return true
+		}
+	}
+	return false
+}
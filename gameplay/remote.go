@@ -0,0 +1,85 @@
+// remote.go
+
+// Seats played from another connection, such as a second terminal connected
+// to catango serve. The game runs where it was started and asks the remote
+// player for each of their moves over their own connection, showing them
+// what happened since they last moved, the board and their hand first. If
+// the connection drops, a bot plays the seat for the rest of the game.
+package gameplay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// remoteSeat plays a seat by asking whoever is on the connection
+type remoteSeat struct {
+	player  *Player
+	conn    io.ReadWriter
+	reader  *bufio.Reader
+	history *[]string // The CLI game's history, to show what is new
+	seen    int       // History entries already shown
+	// Plays once the connection is gone
+	fallback Agent
+	gone     bool
+}
+
+func newRemoteSeat(player *Player, conn io.ReadWriter, history *[]string, fallback Agent) *remoteSeat {
+	fmt.Fprintf(conn, "You are %s. Wait for your turn...\n", player)
+	return &remoteSeat{player: player, conn: conn, reader: bufio.NewReader(conn), history: history, fallback: fallback}
+}
+
+// ChooseAction shows the remote player the game and asks for a move by its
+// number, as chooseAction does at the terminal
+func (rs *remoteSeat) ChooseAction(game *CatanGame, legal []Action) Action {
+	if rs.gone {
+		return rs.fallback.ChooseAction(game, legal)
+	}
+	for _, event := range (*rs.history)[rs.seen:] {
+		fmt.Fprintln(rs.conn, event)
+	}
+	rs.seen = len(*rs.history)
+	fmt.Fprint(rs.conn, RenderBoard(game, true))
+	printHand(rs.conn, rs.player)
+	printMenu(rs.conn, game, legal, 80)
+	for {
+		answer, err := rs.ask(fmt.Sprintf("%s, choose 1 to %d: ", rs.player, len(legal)))
+		if err != nil {
+			return rs.fallback.ChooseAction(game, legal)
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(legal) {
+			fmt.Fprintf(rs.conn, "%q is not one of the choices, please try again.\n", answer)
+			continue
+		}
+		return legal[n-1]
+	}
+}
+
+// answer asks the remote player a yes or no question, taking no for an
+// answer once the connection is gone
+func (rs *remoteSeat) answer(question string) string {
+	answer, err := rs.ask(question)
+	if err != nil {
+		return "no"
+	}
+	return answer
+}
+
+// ask reads one line from the connection, marking the seat gone if it
+// cannot
+func (rs *remoteSeat) ask(prompt string) (string, error) {
+	if rs.gone {
+		return "", ErrInputClosed
+	}
+	fmt.Fprint(rs.conn, prompt)
+	line, err := rs.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		rs.gone = true
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,114 @@
+// save.go
+
+// Games in progress saved to a JSON file and loaded again. The board and the
+// pieces on it are kept as a BoardFile (see boardFile.go), and everything else
+// as the game's own fields: hands, the bank, whose turn it is and the phase.
+package gameplay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type SavedGame struct {
+	Board *BoardFile `json:"board"`
+	Game  *CatanGame `json:"game"`
+	Seats []Seat     `json:"seats,omitempty"` // Who plays each seat, see seats.go
+}
+
+// SaveGame writes the whole game state as indented JSON
+func SaveGame(game *CatanGame, path string) error {
+	return SaveSession(game, nil, path)
+}
+
+// SaveSession saves a game with its seats, so bots carry on as bots when it
+// is loaded with LoadSession
+func SaveSession(game *CatanGame, seats []Seat, path string) error {
+	data, err := json.MarshalIndent(SavedGame{Board: BoardFileFrom(game, ""), Game: game, Seats: seats}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadGame reads a game saved by SaveGame, ready to carry on from where it
+// was saved
+func LoadGame(path string) (*CatanGame, error) {
+	game, _, err := LoadSession(path)
+	return game, err
+}
+
+// LoadSession reads a game and its seats, which are nil if none were saved
+func LoadSession(path string) (*CatanGame, []Seat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	game, err := saved.restore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if saved.Seats != nil {
+		if err := ValidateSeats(saved.Seats); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return game, saved.Seats, nil
+}
+
+// ParseSavedGame decodes a saved game and puts its pieces back on the board.
+// Points and hands are taken as saved rather than worked out again.
+func ParseSavedGame(data []byte) (*CatanGame, error) {
+	var saved SavedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	return saved.restore()
+}
+
+func (saved SavedGame) restore() (*CatanGame, error) {
+	if saved.Board == nil || saved.Game == nil || saved.Game.Bank == nil || len(saved.Game.Players) == 0 {
+		return nil, errors.New("the board, bank or players are missing")
+	}
+	board, err := saved.Board.Board()
+	if err != nil {
+		return nil, fmt.Errorf("board: %w", err)
+	}
+	game := saved.Game
+	game.Board = board
+
+	owner := func(id int) *Player {
+		if player := GetPlayerByID(game, id); player != nil {
+			return player
+		}
+		return GetNeutralByID(game, id)
+	}
+	for i, entry := range saved.Board.Buildings {
+		player := owner(entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("board: buildings[%d]: there is no player %d", i, entry.Player)
+		}
+		setBuilding(game, entry.Vertex, player, buildingTypes[entry.Type])
+	}
+	for i, entry := range saved.Board.Roads {
+		player := owner(entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("board: roads[%d]: there is no player %d", i, entry.Player)
+		}
+		setRoad(game, board.Graph.EdgeBetween(entry.Vertices[0], entry.Vertices[1]), player)
+	}
+	for i, entry := range saved.Board.Ships {
+		player := owner(entry.Player)
+		if player == nil {
+			return nil, fmt.Errorf("board: ships[%d]: there is no player %d", i, entry.Player)
+		}
+		setShip(game, board.Graph.EdgeBetween(entry.Vertices[0], entry.Vertices[1]), player)
+	}
+	return game, nil
+}
@@ -0,0 +1,31 @@
+package gameplay
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	for _, players := range [][]int{{1, 2}, {1, 2, 3, 4}} {
+		rng := rand.New(rand.NewSource(5))
+		game := NewSeededCatanGame(players, 5)
+		BeginSetup(game, game.Players[0])
+		bot := &GreedyBot{Rand: rng, Epsilon: 0.2}
+		for game.TurnCount < 30 && game.Phase != "finished" {
+			applyAction(game, bot.ChooseAction(game, LegalActions(game)), rng)
+		}
+
+		path := filepath.Join(t.TempDir(), "game.json")
+		if err := SaveGame(game, path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadGame(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !loaded.Equal(game) {
+			t.Errorf("%d player game changed in saving and loading", len(players))
+		}
+	}
+}
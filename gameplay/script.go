@@ -0,0 +1,280 @@
+// script.go
+
+// Scripts: plain text files that play a CLI game, so a bug report or a
+// regression test is a file anyone can read and run. Each line is what a
+// player types at the prompt, with a few lines of its own:
+//
+//	# A comment, as is anything after a #
+//	seed 42              The seed to play with, before any input
+//	enter                Just ENTER, as blank lines are skipped
+//	expect vp 2 = 3      Stops the game unless player 2 has 3 points
+//
+// An expectation is checked when the game asks for the input after it, so
+// it sees everything the lines before it did, bot moves included. It names
+// a quantity, a player by ID or name for the quantities about one player, a
+// comparison (= != < > <= >=) and a value:
+//
+//	vp, cards, dev, knights, tokens, roads, ships, settlements, cities, brick,
+//	lumber, wool, grain or ore, and the other names of resources, of a player
+//	phase, turn, roll, robber, winner of the game
+package gameplay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Script is read as a CLIGame's Input, see NewScript
+type Script struct {
+	Seed    int64 // From a seed line, 0 if there is none
+	lines   []scriptLine
+	next    int
+	pending []byte // The rest of a line too long for the last Read
+	game    *CatanGame
+}
+
+type scriptLine struct {
+	number int
+	text   string       // Input, or the expectation as written
+	expect *expectation // Nil for input
+}
+
+type expectation struct {
+	quantity string
+	player   string // ID or name, empty for quantities of the game
+	compare  string
+	value    string
+}
+
+// Quantities expectations can check about a player
+var playerQuantities = map[string]func(game *CatanGame, player *Player) int{
+	"vp":          func(_ *CatanGame, p *Player) int { return TotalVictoryPoints(p) },
+	"cards":       func(_ *CatanGame, p *Player) int { return handSize(p) },
+	"dev":         func(_ *CatanGame, p *Player) int { return devCardCount(p) },
+	"knights":     func(_ *CatanGame, p *Player) int { return p.KnightsPlayed },
+	"tokens":      func(_ *CatanGame, p *Player) int { return p.TradeTokens },
+	"roads":       countRoads,
+	"ships":       countShips,
+	"settlements": func(g *CatanGame, p *Player) int { return countBuildings(g, p, buildingTypes["settlement"]) },
+	"cities":      func(g *CatanGame, p *Player) int { return countBuildings(g, p, buildingTypes["city"]) },
+}
+
+// And about the game, as text so phases can be compared
+var gameQuantities = map[string]func(game *CatanGame) string{
+	"phase":  func(g *CatanGame) string { return g.Phase },
+	"turn":   func(g *CatanGame) string { return strconv.Itoa(CurrentPlayer(g).ID) },
+	"roll":   func(g *CatanGame) string { return strconv.Itoa(g.LastRoll) },
+	"robber": func(g *CatanGame) string { return strconv.Itoa(int(g.Board.RobberPosition)) },
+	"winner": func(g *CatanGame) string { return strconv.Itoa(g.WinnerID) },
+}
+
+var comparisons = map[string]func(a, b int) bool{
+	"=":  func(a, b int) bool { return a == b },
+	"!=": func(a, b int) bool { return a != b },
+	"<":  func(a, b int) bool { return a < b },
+	">":  func(a, b int) bool { return a > b },
+	"<=": func(a, b int) bool { return a <= b },
+	">=": func(a, b int) bool { return a >= b },
+}
+
+// LoadScript reads a script file
+func LoadScript(path string) (*Script, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	script, err := NewScript(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+// NewScript reads a whole script, checking every line can be run
+func NewScript(r io.Reader) (*Script, error) {
+	script := &Script{}
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		words := strings.Fields(text)
+		if len(words) == 0 {
+			continue
+		}
+		line := scriptLine{number: number, text: strings.Join(words, " ")}
+		switch strings.ToLower(words[0]) {
+		case "seed":
+			seed, err := strconv.ParseInt(strings.Join(words[1:], ""), 10, 64)
+			if err != nil || len(words) != 2 {
+				return nil, fmt.Errorf("line %d: expected seed <number>", number)
+			}
+			for _, earlier := range script.lines {
+				if earlier.expect == nil {
+					return nil, fmt.Errorf("line %d: the seed has to come before any input", number)
+				}
+			}
+			script.Seed = seed
+			continue
+		case "enter":
+			if len(words) == 1 {
+				line.text = ""
+			}
+		case "expect":
+			expect, err := parseExpectation(words[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			line.expect = expect
+		}
+		script.lines = append(script.lines, line)
+	}
+	return script, scanner.Err()
+}
+
+func parseExpectation(words []string) (*expectation, error) {
+	usage := errors.New("expected expect <quantity> [player] <comparison> <value>, like expect vp 2 = 3")
+	if len(words) < 3 {
+		return nil, usage
+	}
+	expect := &expectation{quantity: strings.ToLower(words[0])}
+	rest := words[1:]
+	if _, ok := comparisons[rest[0]]; !ok {
+		expect.player, rest = rest[0], rest[1:]
+	}
+	if len(rest) != 2 || comparisons[rest[0]] == nil {
+		return nil, usage
+	}
+	expect.compare, expect.value = rest[0], rest[1]
+
+	_, ofPlayer := playerQuantities[expect.quantity]
+	_, isResource := resourceNames[expect.quantity]
+	_, ofGame := gameQuantities[expect.quantity]
+	switch {
+	case ofPlayer || isResource:
+		if expect.player == "" {
+			return nil, fmt.Errorf("whose %s? expected expect %s <player> %s %s", expect.quantity, expect.quantity, expect.compare, expect.value)
+		}
+		if _, err := strconv.Atoi(expect.value); err != nil {
+			return nil, fmt.Errorf("%s is counted, %q is not a number", expect.quantity, expect.value)
+		}
+	case ofGame:
+		if expect.player != "" {
+			return nil, fmt.Errorf("%s is about the game, not player %s", expect.quantity, expect.player)
+		}
+		if expect.quantity == "phase" && expect.compare != "=" && expect.compare != "!=" {
+			return nil, fmt.Errorf("phases can only be compared with = or !=")
+		}
+	default:
+		return nil, fmt.Errorf("%q cannot be checked", expect.quantity)
+	}
+	return expect, nil
+}
+
+// Read gives the game one line of input at a time, checking the
+// expectations before it, so they are checked no sooner than the game gets
+// to them
+func (s *Script) Read(p []byte) (int, error) {
+	if len(s.pending) == 0 {
+		for s.next < len(s.lines) && s.lines[s.next].expect != nil {
+			line := s.lines[s.next]
+			s.next++
+			if err := s.check(line); err != nil {
+				return 0, err
+			}
+		}
+		if s.next == len(s.lines) {
+			return 0, io.EOF
+		}
+		s.pending = []byte(s.lines[s.next].text + "\n")
+		s.next++
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Finish checks the expectations after the last input the game read, for
+// a game that ended before asking for more. Input left unread is an error.
+func (s *Script) Finish() error {
+	for ; s.next < len(s.lines); s.next++ {
+		line := s.lines[s.next]
+		if line.expect == nil {
+			return fmt.Errorf("script line %d: the game ended before %q", line.number, line.text)
+		}
+		if err := s.check(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Script) check(line scriptLine) error {
+	fail := func(format string, a ...any) error {
+		return fmt.Errorf("script line %d: %s: %s", line.number, line.text, fmt.Sprintf(format, a...))
+	}
+	if s.game == nil {
+		return fail("there is no game yet")
+	}
+	expect := line.expect
+	var actual string
+	if count, ok := gameQuantities[expect.quantity]; ok {
+		actual = count(s.game)
+	} else {
+		player := s.player(expect.player)
+		if player == nil {
+			return fail("there is no player %s", expect.player)
+		}
+		if resource, ok := resourceNames[expect.quantity]; ok {
+			actual = strconv.Itoa(player.Resources[resource])
+		} else {
+			actual = strconv.Itoa(playerQuantities[expect.quantity](s.game, player))
+		}
+	}
+
+	value := expect.value
+	if expect.quantity == "turn" || expect.quantity == "winner" {
+		if player := s.player(value); player != nil {
+			value = strconv.Itoa(player.ID)
+		}
+	}
+	a, aErr := strconv.Atoi(actual)
+	b, bErr := strconv.Atoi(value)
+	var ok bool
+	switch {
+	case aErr == nil && bErr == nil:
+		ok = comparisons[expect.compare](a, b)
+	case expect.compare == "=" || expect.compare == "!=":
+		ok = (actual == value) == (expect.compare == "=")
+	default:
+		return fail("%q is not a number", value)
+	}
+	if !ok {
+		return fail("it is %s", actual)
+	}
+	return nil
+}
+
+// A player by ID, or by name ignoring case
+func (s *Script) player(word string) *Player {
+	if id, err := strconv.Atoi(word); err == nil {
+		return GetPlayerByID(s.game, id)
+	}
+	for _, player := range s.game.Players {
+		if player.Name != "" && strings.EqualFold(player.Name, word) {
+			return player
+		}
+	}
+	return nil
+}
+
+// Points a script being read at the game its expectations are about
+func (cg *CLIGame) follow(game *CatanGame) {
+	if script, ok := cg.Input.(*Script); ok {
+		script.game = game
+	}
+}
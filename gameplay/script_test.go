@@ -0,0 +1,92 @@
+package gameplay
+
+import (
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewScript(t *testing.T) {
+	script, err := NewScript(strings.NewReader("# players\nseed 3\n\n3 # three\nenter\nexpect vp 2 >= 2\nexpect phase = main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script.Seed != 3 || len(script.lines) != 4 || script.lines[0].text != "3" || script.lines[1].text != "" {
+		t.Errorf("got seed %d and lines %+v", script.Seed, script.lines)
+	}
+
+	for _, bad := range []string{
+		"expect vp = 2",
+		"expect phase 2 = main",
+		"expect luck 1 = 2",
+		"expect vp 1 = many",
+		"expect vp 1 2",
+		"r\nseed 4",
+	} {
+		if _, err := NewScript(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+// Expectations are checked when the game reads the line after them
+func TestScriptExpectations(t *testing.T) {
+	game := setUpGame(t)
+	game.Players[1].Name = "Bo"
+	script, err := NewScript(strings.NewReader("expect vp 1 = 2\nexpect vp Bo = 2\nexpect turn != Bo\nr\nexpect phase = setup\nend\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg := &CLIGame{
+		Input:    script,
+		Output:   &strings.Builder{},
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(2)), // Not a seven, so the robber does not move
+	}
+	err = cg.Play(game)
+	if err == nil || errors.Is(err, ErrInputClosed) || !strings.Contains(err.Error(), "script line 5: expect phase = setup: it is main") {
+		t.Errorf("got %v, expected the phase expectation to fail", err)
+	}
+	if !game.HasRolled {
+		t.Error("the roll before the failed expectation was not played")
+	}
+}
+
+// The scripts in testdata play through without an expectation failing
+func TestScriptFiles(t *testing.T) {
+	paths, _ := filepath.Glob("testdata/*.txt")
+	if len(paths) == 0 {
+		t.Fatal("there are no scripts")
+	}
+	for _, path := range paths {
+		script, err := LoadScript(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var output strings.Builder
+		cg := &CLIGame{Input: script, Output: &output, SavePath: filepath.Join(t.TempDir(), "save.json"), HotSeat: true}
+		count, err := cg.Initialize()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		seats, err := cg.AskSeats(count)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		game, startingPlayer, err := cg.NewGame(seats, script.Seed)
+		if err == nil {
+			err = cg.SnakeBuild(game, startingPlayer)
+		}
+		if err == nil {
+			err = cg.Play(game)
+		}
+		if errors.Is(err, ErrInputClosed) {
+			err = script.Finish()
+		}
+		if err != nil {
+			t.Errorf("%s: %v\n%s", path, err, output.String())
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// seafarers.go
+
+// The Seafarers expansion: sea tiles between islands, ships that sail along
+// the edges next to the sea, and bonus points for settling a new island. A
+// board without sea tiles plays exactly like the base game.
+//
+// Ships follow the same building rules as roads, but on edges that touch the
+// sea rather than land, and a line of ships only joins a line of roads at one
+// of the player's own buildings. Once per turn a player may move the ship at
+// the open end of a line, as long as it was not built that turn. The pirate
+// is not modelled; the robber stays on land.
+package gameplay
+
+import "sort"
+
+// Sea is the resource of a sea tile, which has no number and produces nothing
+const Sea = "Sea"
+
+const MaxShips = 15
+
+// Produces reports whether a tile can have a number and pay out resources
+func (t *Tile) Produces() bool {
+	return t.Resource != "D" && t.Resource != Sea
+}
+
+// Where the land and sea are, worked out once per board by indexTerrain. It
+// never changes during a game, so clones share it.
+type terrain struct {
+	tileIslands   []int   // Island of each tile by index into Board.Tiles, -1 for sea
+	vertexIslands []int   // Island touching each vertex by ID, -1 if it only touches sea
+	edgeKinds     []uint8 // Bit 1 if the edge touches land, bit 2 if it touches the sea
+}
+
+const (
+	edgeLand uint8 = 1 << iota
+	edgeSea
+)
+
+// indexTerrain finds the islands of a board with sea tiles. Islands are
+// groups of touching land tiles numbered largest first, so island 0 is the
+// home island the game starts on. The edge of the board counts as sea.
+func indexTerrain(board *Board) {
+	board.terrain = nil
+	hasSea := false
+	for _, tile := range board.Tiles {
+		hasSea = hasSea || tile.Resource == Sea
+	}
+	if !hasSea {
+		return
+	}
+
+	neighbours := tileNeighbours(board)
+	var islands [][]int
+	seen := make([]bool, len(board.Tiles))
+	for start, tile := range board.Tiles {
+		if seen[start] || tile.Resource == Sea {
+			continue
+		}
+		island := []int{start}
+		seen[start] = true
+		for next := 0; next < len(island); next++ {
+			for _, j := range neighbours[island[next]] {
+				if !seen[j] && board.Tiles[j].Resource != Sea {
+					seen[j] = true
+					island = append(island, j)
+				}
+			}
+		}
+		islands = append(islands, island)
+	}
+	sort.SliceStable(islands, func(i, j int) bool { return len(islands[i]) > len(islands[j]) })
+
+	graph := board.Graph
+	t := &terrain{
+		tileIslands:   make([]int, len(board.Tiles)),
+		vertexIslands: make([]int, graph.VertexCount()+1),
+		edgeKinds:     make([]uint8, graph.EdgeCount()),
+	}
+	for i := range t.tileIslands {
+		t.tileIslands[i] = -1
+	}
+	for number, island := range islands {
+		for _, i := range island {
+			t.tileIslands[i] = number
+		}
+	}
+	for vertexID := 1; vertexID <= graph.VertexCount(); vertexID++ {
+		t.vertexIslands[vertexID] = -1
+		for _, tileID := range graph.layout.vertices[vertexID].TileIds {
+			if island := t.tileIslands[tileID-1]; island >= 0 {
+				t.vertexIslands[vertexID] = island
+			}
+		}
+	}
+	for edge := range t.edgeKinds {
+		a, b := graph.EdgeVertices(edge)
+		shared := sharedTiles(graph, a, b)
+		if len(shared) == 1 {
+			t.edgeKinds[edge] |= edgeSea
+		}
+		for _, tileID := range shared {
+			if board.Tiles[tileID-1].Resource == Sea {
+				t.edgeKinds[edge] |= edgeSea
+			} else {
+				t.edgeKinds[edge] |= edgeLand
+			}
+		}
+	}
+	board.terrain = t
+}
+
+// Tiles on either side of the edge between two vertices
+func sharedTiles(graph *Graph, vertexID1, vertexID2 int) []TileID {
+	var shared []TileID
+	for _, tileID := range graph.layout.vertices[vertexID1].TileIds {
+		for _, other := range graph.layout.vertices[vertexID2].TileIds {
+			if tileID == other {
+				shared = append(shared, tileID)
+			}
+		}
+	}
+	return shared
+}
+
+// HasSea reports whether the board has sea tiles, and so ships
+func HasSea(board *Board) bool {
+	return board.terrain != nil
+}
+
+// VertexIsland is the island a vertex is on, -1 if it only touches the sea.
+// Every vertex of a board without sea is on island 0.
+func VertexIsland(board *Board, vertexID int) int {
+	if board.terrain == nil {
+		return 0
+	}
+	return board.terrain.vertexIslands[vertexID]
+}
+
+// Roads need land on at least one side
+func landEdge(board *Board, edge int) bool {
+	return board.terrain == nil || board.terrain.edgeKinds[edge]&edgeLand != 0
+}
+
+// Ships need sea on at least one side
+func seaEdge(board *Board, edge int) bool {
+	return board.terrain != nil && board.terrain.edgeKinds[edge]&edgeSea != 0
+}
+
+// Records the island a new settlement is on. The first settlement a player
+// builds on an island after setup earns the game's IslandBonus.
+func settleIsland(game *CatanGame, player *Player, vertexID int) {
+	island := VertexIsland(game.Board, vertexID)
+	if player.Neutral || island < 0 || island >= 64 {
+		return
+	}
+	bit := uint64(1) << island
+	if player.Islands&bit == 0 && game.Phase != "setup" {
+		player.VictoryPoints += game.IslandBonus
+	}
+	player.Islands |= bit
+}
+
+// A ship is connected if one of its ends has the player's building, or has
+// another of the player's ships that is not cut off by an opponent's building
+func shipConnects(vertexID1, vertexID2 int, player *Player, game *CatanGame) bool {
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for _, end := range [2]int{vertexID1, vertexID2} {
+		if graph.vertexOwners[end] == slot {
+			return true
+		}
+		if graph.vertexOwners[end] != 0 {
+			continue
+		}
+		for _, edge := range graph.layout.vertexEdges[end] {
+			if graph.shipOwners[edge] == slot {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Every edge the player could build a ship on, ignoring cost
+func ComputeValidShipPlacements(game *CatanGame, player *Player) [][2]int {
+	var ships [][2]int
+	if !HasSea(game.Board) || countShips(game, player) >= MaxShips {
+		return ships
+	}
+
+	graph := game.Board.Graph
+	for edge := 0; edge < graph.EdgeCount(); edge++ {
+		a, b := graph.EdgeVertices(edge)
+		if seaEdge(game.Board, edge) && RoadEmptySpace(a, b, game) && shipConnects(a, b, player, game) {
+			ships = append(ships, [2]int{a, b})
+		}
+	}
+
+	return ships
+}
+
+// Validates that the player can build a ship and pays for it
+func ValidateAndPlaceShip(vertexID1, vertexID2 int, player *Player, game *CatanGame) bool {
+	edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2)
+	if countShips(game, player) >= MaxShips || !RoadEmptySpace(vertexID1, vertexID2, game) || !seaEdge(game.Board, edge) ||
+		!shipConnects(vertexID1, vertexID2, player, game) || !CanPlayerAfford(player, "ship") {
+		return false
+	}
+	payCost(game, player, "ship")
+	PlaceShip(vertexID1, vertexID2, player, game)
+	game.NewShips = append(game.NewShips, edge)
+	return true
+}
+
+// Assumes validation has already been done
+func PlaceShip(vertexID1, vertexID2 int, player *Player, game *CatanGame) {
+	if edge := game.Board.Graph.EdgeBetween(vertexID1, vertexID2); edge >= 0 {
+		setShip(game, edge, player)
+	}
+}
+
+func countShips(game *CatanGame, player *Player) int {
+	count := 0
+	slot := playerSlot(game, player)
+	for _, owner := range game.Board.Graph.shipOwners {
+		if owner == slot && slot != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// MovableShips are the player's ships that could be moved now: at the open
+// end of a line, with neither the player's building nor another of their
+// ships on one end, and not built this turn. Only one ship moves per turn.
+func MovableShips(game *CatanGame, player *Player) [][2]int {
+	var ships [][2]int
+	if game.ShipMoved {
+		return ships
+	}
+
+	graph := game.Board.Graph
+	slot := playerSlot(game, player)
+	for edge, owner := range graph.shipOwners {
+		if owner != slot || slot == 0 || containsEdge(game.NewShips, edge) {
+			continue
+		}
+		a, b := graph.EdgeVertices(edge)
+		for _, end := range [2]int{a, b} {
+			if graph.vertexOwners[end] != slot && !hasOtherShip(graph, end, edge, slot) {
+				ships = append(ships, [2]int{a, b})
+				break
+			}
+		}
+	}
+	return ships
+}
+
+func hasOtherShip(graph *Graph, vertexID, edge int, slot uint8) bool {
+	for _, other := range graph.layout.vertexEdges[vertexID] {
+		if other != edge && graph.shipOwners[other] == slot {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEdge(edges []int, edge int) bool {
+	for _, e := range edges {
+		if e == edge {
+			return true
+		}
+	}
+	return false
+}
+
+// Every move of a movable ship to another edge it could be built on
+func shipMoveActions(game *CatanGame, player *Player) []Action {
+	var actions []Action
+	graph := game.Board.Graph
+	for _, from := range MovableShips(game, player) {
+		edge := graph.EdgeBetween(from[0], from[1])
+		setShip(game, edge, nil) // Lift the ship so it cannot support itself
+		for _, to := range ComputeValidShipPlacements(game, player) {
+			if to != from {
+				actions = append(actions, Action{Type: ActionMoveShip, PlayerID: player.ID, MoveFrom: from, VertexID: to[0], VertexID2: to[1]})
+			}
+		}
+		setShip(game, edge, player)
+	}
+	return actions
+}
+
+// Assumes the move came from shipMoveActions
+func moveShip(game *CatanGame, player *Player, from [2]int, vertexID1, vertexID2 int) {
+	graph := game.Board.Graph
+	setShip(game, graph.EdgeBetween(from[0], from[1]), nil)
+	PlaceShip(vertexID1, vertexID2, player, game)
+	game.ShipMoved = true
+}
+
+// Tiles of the three small islands of the Seafarers board, each round a
+// corner five steps from the centre
+var seafarersIslands = []HexCoord{
+	{5, -1}, {5, 0}, {4, 1},
+	{-5, 4}, {-5, 5}, {-4, 5},
+	{-1, -4}, {0, -5}, {1, -5},
+}
+
+// SeafarersBoard is a New Shores style scenario: the base board as the home
+// island, two rings of sea round it, and three small islands beyond them,
+// each worth IslandBonus points to the first settlement a player builds there
+var SeafarersBoard = func() BoardSpec {
+	spec := BaseBoard
+	spec.Name = "seafarers"
+	spec.MinPlayers = 3
+	spec.Shape = append(HexagonShape(4), seafarersIslands...)
+	spec.SpiralTokens = nil
+	spec.PortPositions = nil
+	spec.IslandBonus = 2
+
+	for _, coord := range HexagonShape(4) {
+		if coord.Distance(HexCoord{}) > 2 {
+			spec.SeaTiles = append(spec.SeaTiles, coord)
+		}
+	}
+
+	spec.Resources = append(append([]string(nil), BaseBoard.Resources...), "W", "W", "L", "L", "O", "O", "B", "B", "S")
+	spec.Tokens = append(append([]int(nil), BaseBoard.Tokens...), 2, 3, 4, 5, 6, 8, 9, 10, 11)
+	return spec
+}()
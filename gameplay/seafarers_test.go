@@ -0,0 +1,161 @@
+package gameplay
+
+import "testing"
+
+func TestSeafarersBoard(t *testing.T) {
+	game, err := NewCatanGameOnBoard([]int{1, 2, 3}, SeafarersBoard, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := game.Board
+	islandTiles := make(map[int]int)
+	for i, tile := range board.Tiles {
+		if tile.Resource == Sea {
+			if tile.NumberToken != 0 || board.terrain.tileIslands[i] != -1 {
+				t.Errorf("sea tile %d has number %d and island %d", tile.ID, tile.NumberToken, board.terrain.tileIslands[i])
+			}
+			continue
+		}
+		islandTiles[board.terrain.tileIslands[i]]++
+	}
+	if len(board.Tiles) != 70 || len(islandTiles) != 4 || islandTiles[0] != 19 || islandTiles[1] != 3 || islandTiles[3] != 3 {
+		t.Errorf("got %d tiles with islands of %v, expected 70 with islands of 19, 3, 3 and 3", len(board.Tiles), islandTiles)
+	}
+	if board.Tiles[board.RobberPosition-1].Resource != "D" {
+		t.Errorf("robber starts on %s", board.Tiles[board.RobberPosition-1].Resource)
+	}
+	if err := ValidatePorts(board); err != nil {
+		t.Error(err)
+	}
+	for _, port := range board.Ports {
+		if board.terrain.tileIslands[port.Tile-1] != 0 {
+			t.Errorf("port on %s is not on the home island", EdgeKey(port.VertexIDs[0], port.VertexIDs[1]))
+		}
+	}
+
+	BeginSetup(game, game.Players[0])
+	for _, action := range LegalActions(game) {
+		if VertexIsland(board, action.VertexID) != 0 {
+			t.Fatalf("%s is not on the home island", action)
+		}
+	}
+}
+
+// Sails from the home island to another one, settles it for the bonus, then
+// moves the ship at the end of the line
+func TestShips(t *testing.T) {
+	game, err := NewCatanGameOnBoard([]int{1, 2, 3}, SeafarersBoard, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, graph := game.Board, game.Board.Graph
+	player := game.Players[0]
+
+	// Shortest line of sea edges from the home island's coast to island 1
+	from := make(map[int]int)
+	var queue []int
+	for vertexID := 1; vertexID <= graph.VertexCount(); vertexID++ {
+		if VertexIsland(board, vertexID) == 0 {
+			for _, edge := range graph.layout.vertexEdges[vertexID] {
+				if seaEdge(board, edge) && from[vertexID] == 0 {
+					from[vertexID] = -1
+					queue = append(queue, vertexID)
+				}
+			}
+		}
+	}
+	target := 0
+	for len(queue) > 0 && target == 0 {
+		vertexID := queue[0]
+		queue = queue[1:]
+		for _, edge := range graph.layout.vertexEdges[vertexID] {
+			next, other := graph.EdgeVertices(edge)
+			if next == vertexID {
+				next = other
+			}
+			if from[next] == 0 && seaEdge(board, edge) {
+				from[next] = vertexID
+				queue = append(queue, next)
+				if VertexIsland(board, next) == 1 {
+					target = next
+				}
+			}
+		}
+	}
+	var line []int
+	for vertexID := target; vertexID != -1; vertexID = from[vertexID] {
+		line = append([]int{vertexID}, line...)
+	}
+	if len(line) < 3 {
+		t.Fatalf("no sea route to island 1, got %v", line)
+	}
+
+	home := line[0]
+	PlaceSettlement(home, player, game) // Still in setup, so no bonus for the home island
+	if player.VictoryPoints != 1 || player.Islands != 1 {
+		t.Fatalf("home settlement gave %d points and islands %b", player.VictoryPoints, player.Islands)
+	}
+	for _, adjID := range graph.Neighbors(home) {
+		if edge := graph.EdgeBetween(home, adjID); !landEdge(board, edge) && RoadEmptySpace(home, adjID, game) {
+			player.Resources["B"], player.Resources["L"] = 1, 1
+			if ValidateAndPlaceRoad(home, adjID, player, game) {
+				t.Errorf("road built out at sea on %s", EdgeKey(home, adjID))
+			}
+		}
+	}
+
+	game.Phase = "main"
+	game.HasRolled = true
+	for i := 0; i+1 < len(line); i++ {
+		player.Resources["L"], player.Resources["S"] = 1, 1
+		a, b := graph.EdgeVertices(graph.EdgeBetween(line[i], line[i+1]))
+		build := Action{Type: ActionBuildShip, PlayerID: player.ID, VertexID: a, VertexID2: b}
+		if err := ApplyAction(game, build, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if countShips(game, player) != len(line)-1 || player.LongestRoad != len(line)-1 {
+		t.Errorf("got %d ships and a route of %d, expected %d", countShips(game, player), player.LongestRoad, len(line)-1)
+	}
+	if len(MovableShips(game, player)) != 0 {
+		t.Error("ships built this turn should not move")
+	}
+
+	player.Resources = map[string]int{"B": 1, "L": 1, "S": 1, "W": 1}
+	if err := ApplyAction(game, Action{Type: ActionBuildSettlement, PlayerID: player.ID, VertexID: target}, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := 2 + game.IslandBonus
+	if game.LongestRoadID == player.ID {
+		expected += 2
+	}
+	if player.VictoryPoints != expected || player.Islands != 3 {
+		t.Errorf("settling island 1 gave %d points and islands %b, expected %d and 11", player.VictoryPoints, player.Islands, expected)
+	}
+
+	// Both ends of the line are now settled, so nothing can move until the
+	// last ship is cut loose
+	endTurn(game)
+	game.TurnIndex = 0
+	game.HasRolled = true
+	if len(MovableShips(game, player)) != 0 {
+		t.Error("a line between two settlements should not move")
+	}
+	setBuilding(game, target, nil, 0)
+	moves := shipMoveActions(game, player)
+	if len(moves) == 0 {
+		t.Fatal("expected the open ship to move")
+	}
+	if err := ApplyAction(game, moves[0], nil); err != nil {
+		t.Fatal(err)
+	}
+	if ShipOwner(game, moves[0].VertexID, moves[0].VertexID2) != player || ShipOwner(game, moves[0].MoveFrom[0], moves[0].MoveFrom[1]) != nil {
+		t.Errorf("%s did not move the ship", moves[0])
+	}
+	if len(shipMoveActions(game, player)) != 0 {
+		t.Error("only one ship may move per turn")
+	}
+	if !game.Clone().Equal(game) {
+		t.Error("clone differs from the game")
+	}
+}
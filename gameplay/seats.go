@@ -0,0 +1,312 @@
+// seats.go
+
+// Who sits at each place in a game: their name, colour, and whether they are
+// a person at this terminal, a bot, or someone playing from elsewhere (see
+// remote.go). Seats can be typed in one line each (see ParseSeat) or read
+// from a JSON file:
+//
+//	{"seats": [
+//	  {"name": "Ann", "color": "blue"},
+//	  {"name": "Bot", "type": "bot", "bot": "hard"}
+//	]}
+package gameplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+type SeatType string
+
+const (
+	SeatHuman  SeatType = "human"
+	SeatBot    SeatType = "bot"
+	SeatRemote SeatType = "remote"
+)
+
+// Bot difficulties and the bots playing them, see NewAgent
+var botDifficulties = map[string]string{
+	"easy":   "random",
+	"medium": "greedy",
+	"hard":   "mcts:300",
+}
+
+type Seat struct {
+	Name  string   `json:"name,omitempty"`  // "Player <ID>" if empty
+	Color string   `json:"color,omitempty"` // One of ColorNames, the default for the seat if empty
+	Type  SeatType `json:"type,omitempty"`  // SeatHuman if empty
+	Bot   string   `json:"bot,omitempty"`   // easy, medium, hard, or a NewAgent name, for bot seats
+}
+
+type seatsFile struct {
+	Seats []Seat `json:"seats"`
+}
+
+// LoadSeats reads seats from a JSON file and checks them
+func LoadSeats(path string) ([]Seat, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file seatsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateSeats(file.Seats); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Seats, nil
+}
+
+// ParseSeat reads a seat typed as words in any order: a colour, a type
+// (human, remote, or a bot difficulty like hard), and the name as whatever
+// is left. An empty line is a human with the defaults.
+func ParseSeat(line string) (Seat, error) {
+	var seat Seat
+	var name []string
+	for _, word := range strings.Fields(line) {
+		lower := strings.ToLower(word)
+		switch {
+		case colorCodes[lower] != "":
+			if seat.Color != "" {
+				return seat, fmt.Errorf("both %s and %s are colours, choose one", seat.Color, lower)
+			}
+			seat.Color = lower
+		case lower == string(SeatHuman) || lower == string(SeatRemote):
+			seat.Type = SeatType(lower)
+		case botDifficulties[lower] != "" || lower == string(SeatBot):
+			seat.Type, seat.Bot = SeatBot, lower
+			if lower == string(SeatBot) {
+				seat.Bot = "medium"
+			}
+		default:
+			name = append(name, word)
+		}
+	}
+	seat.Name = strings.Join(name, " ")
+	return seat, nil
+}
+
+// ValidateSeats checks there are enough seats for a game, names and colours
+// are not shared, and every bot can be built
+func ValidateSeats(seats []Seat) error {
+	if len(seats) < BaseBoard.MinPlayers || len(seats) > ExtensionBoard.MaxPlayers {
+		return fmt.Errorf("a game needs %d to %d seats, not %d", BaseBoard.MinPlayers, ExtensionBoard.MaxPlayers, len(seats))
+	}
+	return checkSeats(seats)
+}
+
+// Everything ValidateSeats checks but the number of seats, so seats can be
+// checked as they are typed in
+func checkSeats(seats []Seat) error {
+	names := make(map[string]int)
+	colors := make(map[string]int)
+	for i, seat := range seats {
+		if seat.Name != "" {
+			if other, taken := names[strings.ToLower(seat.Name)]; taken {
+				return fmt.Errorf("seats %d and %d are both called %s", other, i+1, seat.Name)
+			}
+			names[strings.ToLower(seat.Name)] = i + 1
+		}
+		if seat.Color != "" {
+			if colorCodes[seat.Color] == "" {
+				return fmt.Errorf("seat %d: unknown colour %q, expected one of %s", i+1, seat.Color, strings.Join(ColorNames, ", "))
+			}
+			if other, taken := colors[seat.Color]; taken {
+				return fmt.Errorf("seats %d and %d are both %s", other, i+1, seat.Color)
+			}
+			colors[seat.Color] = i + 1
+		}
+
+		switch seat.Type {
+		case "", SeatHuman, SeatRemote:
+			if seat.Bot != "" {
+				return fmt.Errorf("seat %d: only bot seats have a bot", i+1)
+			}
+		case SeatBot:
+			if _, err := seatAgent(seat, nil); err != nil {
+				return fmt.Errorf("seat %d: %w", i+1, err)
+			}
+		default:
+			return fmt.Errorf("seat %d: unknown type %q, expected human, bot or remote", i+1, seat.Type)
+		}
+	}
+	return nil
+}
+
+// The colour each seat plays in: its own, or else the first colour from the
+// seat's default on that no seat has chosen or been given
+func seatColors(seats []Seat) []string {
+	colors := make([]string, len(seats))
+	taken := make(map[string]bool)
+	for i, seat := range seats {
+		colors[i] = seat.Color
+		taken[seat.Color] = true
+	}
+	for i := range colors {
+		for j := 0; colors[i] == "" && j < len(ColorNames); j++ {
+			if color := ColorNames[(i+j)%len(ColorNames)]; !taken[color] {
+				colors[i], taken[color] = color, true
+			}
+		}
+	}
+	return colors
+}
+
+// ApplySeats names and colours the game's players, seat i being Players[i]
+func ApplySeats(game *CatanGame, seats []Seat) {
+	colors := seatColors(seats)
+	for i, player := range game.Players {
+		if i < len(seats) {
+			player.Name = seats[i].Name
+			player.Color = colors[i]
+		}
+	}
+}
+
+// ValidateBot checks a bot can be built from a difficulty or a NewAgent name
+func ValidateBot(bot string) error {
+	_, err := seatAgent(Seat{Type: SeatBot, Bot: bot}, nil)
+	return err
+}
+
+// The bot playing a bot seat
+func seatAgent(seat Seat, rng *rand.Rand) (Agent, error) {
+	name := seat.Bot
+	if bot, ok := botDifficulties[name]; ok {
+		name = bot
+	}
+	if name == "" {
+		name = botDifficulties["medium"]
+	}
+	return NewAgent(name, rng)
+}
+
+// A player's name with their ID, which is how the board shows their pieces
+func playerLabel(player *Player) string {
+	if player.Name == "" {
+		return player.String()
+	}
+	return fmt.Sprintf("%s (%d)", player.Name, player.ID)
+}
+
+// Players by ID as playerLabel names them, for lists of who can be chosen
+func playerLabels(game *CatanGame, ids []int) string {
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = playerLabel(GetPlayerByID(game, id))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// An action as Action.String has it, but with the other player involved
+// called by their name
+func describeAction(game *CatanGame, action Action) string {
+	return action.Describe(func(playerID int) string {
+		player := GetPlayerByID(game, playerID)
+		if player == nil {
+			player = GetNeutralByID(game, playerID)
+		}
+		if player == nil {
+			return fmt.Sprintf("Player %d", playerID)
+		}
+		return player.String()
+	})
+}
+
+// SetSeats names and colours the game's players from seats, one for each,
+// and has bots play the bot seats from then on. Remote seats are played
+// over the connection Join gives for each, and are refused without it.
+func (cg *CLIGame) SetSeats(game *CatanGame, seats []Seat) error {
+	if len(seats) != len(game.Players) {
+		return fmt.Errorf("there are %d seats for %d players", len(seats), len(game.Players))
+	}
+	if err := ValidateSeats(seats); err != nil {
+		return err
+	}
+	if cg.Rand == nil {
+		cg.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	agents := make(map[int]Agent)
+	for i, seat := range seats {
+		if seat.Type == SeatBot {
+			agent, err := seatAgent(seat, cg.Rand)
+			if err != nil {
+				return fmt.Errorf("seat %d: %w", i+1, err)
+			}
+			agents[game.Players[i].ID] = agent
+		}
+	}
+	ApplySeats(game, seats)
+	for i, seat := range seats {
+		if seat.Type != SeatRemote {
+			continue
+		}
+		if cg.Join == nil {
+			return fmt.Errorf("seat %d is remote, which only catango serve can play, make it a human or a bot", i+1)
+		}
+		conn, err := cg.Join(i+1, seat)
+		if err != nil {
+			return fmt.Errorf("seat %d: %w", i+1, err)
+		}
+		player := game.Players[i]
+		agents[player.ID] = newRemoteSeat(player, conn, &cg.history, &GreedyBot{Rand: cg.Rand})
+	}
+	cg.Seats, cg.agents = seats, agents
+	return nil
+}
+
+// AskSeats asks who sits at each of count seats, one line each, asking
+// again when a seat clashes with one before it
+func (cg *CLIGame) AskSeats(count int) ([]Seat, error) {
+	fmt.Fprintln(cg.out(), "Who is playing? Type a name, a colour, and human, remote, easy, medium or hard for a bot, in any order.")
+	var seats []Seat
+	for len(seats) < count {
+		n := len(seats) + 1
+		line, err := cg.readLine(fmt.Sprintf("Seat %d (ENTER for Player %d, %s, human): ", n, n, seatColors(append(seats, Seat{}))[n-1]))
+		if err != nil {
+			return nil, err
+		}
+		seat, err := ParseSeat(line)
+		if err == nil {
+			err = checkSeats(append(seats[:len(seats):len(seats)], seat))
+		}
+		if err != nil {
+			fmt.Fprintln(cg.out(), err)
+			continue
+		}
+		seats = append(seats, seat)
+	}
+	return seats, nil
+}
+
+// botMove plays the current player's move if they are a bot, saying whether
+// they were
+func (cg *CLIGame) botMove(game *CatanGame) bool {
+	agent := cg.agents[CurrentPlayer(game).ID]
+	if agent == nil {
+		return false
+	}
+	cg.apply(game, agent.ChooseAction(game, LegalActions(game)))
+	return true
+}
+
+// Bots take an offer they can pay when it gets them at least as many cards
+// as they give
+func botAccepts(partner *Player, give, get map[string]int) bool {
+	given, got := 0, 0
+	for resource, amount := range get {
+		if partner.Resources[resource] < amount {
+			return false
+		}
+		given += amount
+	}
+	for _, amount := range give {
+		got += amount
+	}
+	return got >= given
+}
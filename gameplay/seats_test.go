@@ -0,0 +1,265 @@
+package gameplay
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSeat(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Seat
+	}{
+		{"", Seat{}},
+		{"Ann Lee blue", Seat{Name: "Ann Lee", Color: "blue"}},
+		{"hard Red robot", Seat{Name: "robot", Color: "red", Type: SeatBot, Bot: "hard"}},
+		{"bot", Seat{Type: SeatBot, Bot: "medium"}},
+		{"human Bo", Seat{Name: "Bo", Type: SeatHuman}},
+		{"Di REMOTE", Seat{Name: "Di", Type: SeatRemote}},
+	}
+	for _, test := range tests {
+		seat, err := ParseSeat(test.line)
+		if err != nil || seat != test.expected {
+			t.Errorf("%q: got %+v, %v, expected %+v", test.line, seat, err, test.expected)
+		}
+	}
+	if _, err := ParseSeat("red blue"); err == nil {
+		t.Error("two colours were accepted")
+	}
+}
+
+func TestValidateSeats(t *testing.T) {
+	tests := []struct {
+		seats    []Seat
+		expected string // Part of the error, or empty if the seats are fine
+	}{
+		{[]Seat{{Name: "Ann"}, {Type: SeatBot, Bot: "mcts:50"}, {Type: SeatHuman}, {Type: SeatRemote}}, ""},
+		{[]Seat{{Type: SeatRemote, Bot: "hard"}, {}}, "only bot seats"},
+		{[]Seat{{Type: "robot"}, {}}, "unknown type"},
+		{[]Seat{{Name: "Ann"}}, "2 to 6 seats"},
+		{[]Seat{{Name: "Ann"}, {Name: "ann"}}, "both called"},
+		{[]Seat{{Color: "red"}, {Color: "red"}}, "both red"},
+		{[]Seat{{Color: "pink"}, {}}, "unknown colour"},
+		{[]Seat{{Type: SeatBot, Bot: "clever"}, {}}, "unknown agent"},
+		{[]Seat{{Bot: "easy"}, {}}, "only bot seats"},
+	}
+	for _, test := range tests {
+		err := ValidateSeats(test.seats)
+		if test.expected == "" && err != nil || test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+			t.Errorf("%+v: got %v, expected %q", test.seats, err, test.expected)
+		}
+	}
+}
+
+// Seats without a colour never get one a seat has chosen
+func TestSeatColors(t *testing.T) {
+	colors := seatColors([]Seat{{Color: "green"}, {}, {}})
+	if strings.Join(colors, " ") != "green blue yellow" {
+		t.Errorf("got colours %q", colors)
+	}
+}
+
+func TestLoadSeats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seats.json")
+	os.WriteFile(path, []byte(`{"seats": [{"name": "Ann", "color": "blue"}, {"name": "Bot", "type": "bot", "bot": "easy"}]}`), 0o644)
+	seats, err := LoadSeats(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seats) != 2 || seats[0].Name != "Ann" || seats[1].Bot != "easy" {
+		t.Errorf("got seats %+v", seats)
+	}
+}
+
+func TestAskSeats(t *testing.T) {
+	var output strings.Builder
+	cg := &CLIGame{Input: strings.NewReader("Ann red\nBo red\nann\nBo hard\n"), Output: &output}
+	seats, err := cg.AskSeats(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seats) != 2 || seats[1] != (Seat{Name: "Bo", Type: SeatBot, Bot: "hard"}) {
+		t.Errorf("got seats %+v", seats)
+	}
+	for _, expected := range []string{"both red", "both called"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%q was not said:\n%s", expected, output.String())
+		}
+	}
+}
+
+// Bots take their own turns and answer offers, and names are shown in place
+// of numbers
+func TestBotSeat(t *testing.T) {
+	game := setUpGame(t)
+	var output strings.Builder
+	cg := &CLIGame{
+		Input:    strings.NewReader("r\noffer 1 W for 1 S to 2\nend\n"),
+		Output:   &output,
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(3)),
+	}
+	seats := []Seat{{Name: "Ann"}, {Name: "Bo", Type: SeatBot, Bot: "easy"}, {Name: "Cy", Color: "cyan", Type: SeatBot}}
+	if err := cg.SetSeats(game, seats); err != nil {
+		t.Fatal(err)
+	}
+	if game.Players[0].Name != "Ann" || game.Players[2].Color != "cyan" {
+		t.Errorf("the seats were not applied: %+v", game.Players[0])
+	}
+	game.Players[0].Resources["W"]++
+	game.Players[1].Resources["S"]++
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if !strings.Contains(output.String(), "Bo, will you give 1 S for 1 W? (y/n) yes") {
+		t.Errorf("the bot did not take the offer:\n%s", output.String())
+	}
+	if !strings.Contains(output.String(), "Bo rolled") || !strings.Contains(output.String(), "Cy rolled") {
+		t.Errorf("the bots did not play their turns:\n%s", output.String())
+	}
+	if CurrentPlayer(game).ID != 1 {
+		t.Errorf("%s is to play, expected the bots to hand back to Ann", CurrentPlayer(game))
+	}
+
+	_, loaded, err := LoadSession(cg.SavePath)
+	if err != nil || len(loaded) != 3 || loaded[1].Bot != "easy" {
+		t.Errorf("got seats %+v, %v from the save", loaded, err)
+	}
+}
+
+// A remote seat is asked for its moves and answers over its own connection,
+// and a bot takes over once the connection closes
+func TestRemoteSeat(t *testing.T) {
+	game := setUpGame(t)
+	var output, remoteOutput strings.Builder
+	remote := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader("YES\n0\n1\n"), &remoteOutput}
+	cg := &CLIGame{
+		Input:    strings.NewReader("r\noffer 1 W for 1 S to 2\nend\n"),
+		Output:   &output,
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(3)),
+	}
+	seats := []Seat{{Name: "Ann"}, {Name: "Bo", Type: SeatRemote}, {Name: "Cy", Type: SeatBot}}
+	if err := cg.SetSeats(game, seats); err == nil {
+		t.Fatal("a remote seat was accepted with nothing to join it")
+	}
+	joined := 0
+	cg.Join = func(number int, seat Seat) (io.ReadWriter, error) {
+		if number != 2 || seat.Name != "Bo" {
+			t.Errorf("seat %d %+v was joined, expected Bo's", number, seat)
+		}
+		joined++
+		return remote, nil
+	}
+	if err := cg.SetSeats(game, seats); err != nil {
+		t.Fatal(err)
+	}
+	if joined != 1 {
+		t.Errorf("the remote seat was joined %d times", joined)
+	}
+	game.Players[0].Resources["W"]++
+	game.Players[1].Resources["S"]++
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if !strings.Contains(output.String(), "Bo, will you give 1 S for 1 W? (y/n) YES") || game.Players[1].Resources["W"] == 0 {
+		t.Errorf("the remote player did not take the offer:\n%s", output.String())
+	}
+	for _, expected := range []string{"You are Bo", "Bo, will you give", "Ann traded 1 W to Bo for 1 S", "\"0\" is not one of the choices", "Bo has "} {
+		if !strings.Contains(remoteOutput.String(), expected) {
+			t.Errorf("the remote player was not shown %q:\n%s", expected, remoteOutput.String())
+		}
+	}
+	if !strings.Contains(output.String(), "Bo rolled") || CurrentPlayer(game).ID != 1 {
+		t.Errorf("the remote turn was not played through to Ann's:\n%s", output.String())
+	}
+}
+
+// Saving from the prompt keeps who sits where
+func TestSaveCommandKeepsSeats(t *testing.T) {
+	game := setUpGame(t)
+	cg := &CLIGame{
+		Input:    strings.NewReader("quit\n"),
+		Output:   &strings.Builder{},
+		SavePath: filepath.Join(t.TempDir(), "save.json"),
+		Rand:     rand.New(rand.NewSource(3)),
+	}
+	seats := []Seat{{Name: "Ann", Color: "green"}, {Name: "Bo", Type: SeatBot, Bot: "hard"}, {Name: "Cy"}}
+	if err := cg.SetSeats(game, seats); err != nil {
+		t.Fatal(err)
+	}
+	if err := cg.Play(game); err != nil {
+		t.Fatal(err)
+	}
+	_, loaded, err := LoadSession(cg.SavePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(seats) {
+		t.Fatalf("got seats %+v from the save", loaded)
+	}
+	for i := range seats {
+		if loaded[i] != seats[i] {
+			t.Errorf("seat %d was saved as %+v, expected %+v", i+1, loaded[i], seats[i])
+		}
+	}
+}
+
+// Menus and explanations name the other player instead of giving their ID
+func TestMenusNamePlayers(t *testing.T) {
+	game := setUpGame(t)
+	ApplySeats(game, []Seat{{Name: "Ann"}, {Name: "Bo"}, {Name: "Cy"}})
+	var robber Action
+	for _, tile := range game.Board.Tiles {
+		if victims := robberVictims(game, game.Players[0], tile.ID); len(victims) > 0 && tile.ID != game.Board.RobberPosition {
+			robber = Action{Type: ActionMoveRobber, PlayerID: 1, TileID: tile.ID, VictimID: victims[0]}
+		}
+	}
+	if robber.VictimID == 0 {
+		t.Fatal("nobody can be robbed")
+	}
+	victim := GetPlayerByID(game, robber.VictimID).Name
+
+	var menu strings.Builder
+	printMenu(&menu, game, []Action{robber}, 80)
+	if !strings.Contains(menu.String(), "stealing from "+victim) {
+		t.Errorf("the menu does not name %s:\n%s", victim, menu.String())
+	}
+
+	game.Phase = "robber"
+	robber.VictimID = 99
+	label := playerLabel(GetPlayerByID(game, robberVictims(game, game.Players[0], robber.TileID)[0]))
+	if err := explainIllegal(game, robber); err == nil || !strings.Contains(err.Error(), label) {
+		t.Errorf("a theft from nobody was explained as %v, expected it to name %s", err, label)
+	}
+}
+
+// Actions name every other player they involve, even one named like another
+// player's number
+func TestDescribeAction(t *testing.T) {
+	game := setUpGame(t)
+	ApplySeats(game, []Seat{{Name: "Ann"}, {Name: "Player 3"}, {Name: "Cy"}})
+	tests := []struct {
+		action   Action
+		expected string
+	}{
+		{Action{Type: ActionMoveRobber, PlayerID: 1, TileID: 4, VictimID: 2}, "stealing from Player 3"},
+		{Action{Type: ActionMoveRobber, PlayerID: 1, TileID: 4, VictimID: 3}, "stealing from Cy"},
+		{Action{Type: ActionForcedTrade, PlayerID: 1, VictimID: 3}, "with Cy"},
+		{Action{Type: ActionGiveBack, PlayerID: 3, Give: "W", VictimID: 1}, "W to Ann"},
+		{Action{Type: ActionNeutralRoad, PlayerID: 1, VertexID: 1, VertexID2: 2, NeutralID: 7}, "1-2 for Player 7"},
+	}
+	for _, test := range tests {
+		if described := describeAction(game, test.action); !strings.HasSuffix(described, test.expected) {
+			t.Errorf("%s was described as %q, expected it to end %q", test.action, described, test.expected)
+		}
+	}
+}
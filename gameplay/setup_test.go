@@ -0,0 +1,78 @@
+package gameplay
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnakeOrder(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3, 4}, 1)
+	order := GenerateSnakeOrder(game, game.Players[2])
+	if fmt.Sprint(order) != "[3 4 1 2 2 1 4 3]" {
+		t.Errorf("got order %v starting from player 3", order)
+	}
+}
+
+// Only the second settlement pays, and every setup road touches the
+// settlement placed just before it
+func TestSetupPayout(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	BeginSetup(game, game.Players[0])
+	for game.Phase == "setup" {
+		legal := LegalActions(game)
+		if game.SetupVertex != 0 {
+			for _, action := range legal {
+				if action.VertexID != game.SetupVertex {
+					t.Fatalf("%s does not touch settlement %d", action, game.SetupVertex)
+				}
+			}
+		}
+		action := legal[len(legal)/2]
+		player := CurrentPlayer(game)
+		before := handSize(player)
+		second := game.SetupIndex >= len(game.Players)
+		if err := ApplyAction(game, action, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := 0
+		if action.Type == ActionSetupSettlement && second {
+			for _, tile := range vertexTiles(game, action.VertexID) {
+				if tile.Produces() {
+					expected++
+				}
+			}
+		}
+		if got := handSize(player) - before; got != expected {
+			t.Errorf("%s gave Player %d %d cards, expected %d", action, player.ID, got, expected)
+		}
+	}
+	if game.Phase != "main" || CurrentPlayer(game).ID != 1 {
+		t.Errorf("setup ended in the %s phase with Player %d to play", game.Phase, CurrentPlayer(game).ID)
+	}
+}
+
+// The CLI setup drives the engine, so the last player can place without
+// the game falling over, and a choice not on the menu is asked for again
+func TestSnakeBuild(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2, 3}, 1)
+	plan := game.Clone()
+	BeginSetup(plan, plan.Players[2])
+	var input strings.Builder
+	input.WriteString("999\n") // Not on the menu, so asked again
+	for plan.Phase == "setup" {
+		legal := LegalActions(plan)
+		fmt.Fprintln(&input, len(legal)) // The last choice on the menu
+		applyAction(plan, legal[len(legal)-1], nil)
+	}
+
+	cg := &CLIGame{Input: strings.NewReader(input.String()), SavePath: filepath.Join(t.TempDir(), "save.json")}
+	if err := cg.SnakeBuild(game, game.Players[2]); err != nil {
+		t.Fatal(err)
+	}
+	if !game.Equal(plan) {
+		t.Error("CLI setup did not place the same pieces as the engine")
+	}
+}
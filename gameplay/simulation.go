@@ -0,0 +1,332 @@
+// simulation.go
+
+// Headless games between bots, for comparing bots against each other and
+// checking how fair the boards are. Each game gets its own seed so any single
+// game can be replayed.
+package gameplay
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+)
+
+type SimulationConfig struct {
+	Games    int
+	Agents   []string // One agent name per seat, see NewAgent
+	Seed     int64    // Game i uses Seed+i
+	Workers  int      // Games played at once
+	MaxTurns int      // Games still running after this many turns have no winner
+
+	Board       string           // Name of the board, see Boards. Empty picks one for the number of agents.
+	Layout      BoardLayout      // Overrides the board's own layout if set
+	BoardFile   *BoardFile       // Play every game on this board instead, see boardFile.go
+	Constraints BoardConstraints // Rules every game's board must follow
+}
+
+// The board every game in the simulation is generated from
+func (config SimulationConfig) boardSpec() (BoardSpec, error) {
+	spec := BoardSpecFor(len(config.Agents))
+	if config.Board != "" {
+		var err error
+		if spec, err = BoardSpecNamed(config.Board); err != nil {
+			return spec, err
+		}
+	}
+	if len(config.Agents) < spec.MinPlayers || len(config.Agents) > spec.MaxPlayers {
+		return spec, fmt.Errorf("the %s board is for %d to %d players, not %d", spec.Name, spec.MinPlayers, spec.MaxPlayers, len(config.Agents))
+	}
+	if config.Layout != "" {
+		layout, err := ParseBoardLayout(string(config.Layout))
+		if err != nil {
+			return spec, err
+		}
+		spec.Layout = layout
+	}
+	spec.Constraints = config.Constraints
+	return spec, nil
+}
+
+// GameResult is what one simulated game produced, indexed by seat
+type GameResult struct {
+	Seed        int64
+	FirstSeat   int
+	WinnerSeat  int // -1 if the game hit MaxTurns
+	Turns       int
+	Points      []int
+	ResourcesIn []int // Resource cards received from rolls
+}
+
+type SimulationReport struct {
+	Config  SimulationConfig
+	Results []GameResult
+}
+
+// Maximum moves in one turn before the player is made to end it, in case a
+// bot keeps trading back and forth
+const maxActionsPerTurn = 100
+
+// RunSimulations plays every game, spread over Workers goroutines
+func RunSimulations(config SimulationConfig) (*SimulationReport, error) {
+	if config.Games < 0 {
+		return nil, fmt.Errorf("cannot play %d games", config.Games)
+	}
+	if len(config.Agents) < 2 {
+		return nil, fmt.Errorf("need at least 2 agents, got %d", len(config.Agents))
+	}
+	for _, name := range config.Agents {
+		if _, err := NewAgent(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := config.boardSpec(); err != nil {
+		return nil, err
+	}
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxTurns < 1 {
+		config.MaxTurns = 500
+	}
+
+	report := &SimulationReport{Config: config, Results: make([]GameResult, config.Games)}
+	errs := make([]error, config.Games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				report.Results[i], errs[i] = simulateGame(config, config.Seed+int64(i))
+			}
+		}()
+	}
+	for i := 0; i < config.Games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+	}
+	return report, nil
+}
+
+func simulateGame(config SimulationConfig, seed int64) (GameResult, error) {
+	rng := rand.New(rand.NewSource(seed))
+	ids := make([]int, len(config.Agents))
+	agents := make(map[int]Agent, len(config.Agents))
+	for seat, name := range config.Agents {
+		ids[seat] = seat + 1
+		agents[seat+1], _ = NewAgent(name, rand.New(rand.NewSource(rng.Int63())))
+	}
+
+	var game *CatanGame
+	var err error
+	if config.BoardFile != nil {
+		game, err = NewCatanGameFromFile(ids, config.BoardFile, seed)
+	} else {
+		spec, _ := config.boardSpec()
+		game, err = NewCatanGameOnBoard(ids, spec, seed)
+	}
+	if err != nil {
+		return GameResult{}, err
+	}
+	first := rng.Intn(len(ids))
+	BeginSetup(game, game.Players[first])
+
+	result := GameResult{
+		Seed:        seed,
+		FirstSeat:   first,
+		WinnerSeat:  -1,
+		Points:      make([]int, len(ids)),
+		ResourcesIn: make([]int, len(ids)),
+	}
+
+	actionsThisTurn := 0
+	for game.Phase != "finished" && game.TurnCount < config.MaxTurns {
+		legal := LegalActions(game)
+		action := agents[CurrentPlayer(game).ID].ChooseAction(game, legal)
+		if actionsThisTurn >= maxActionsPerTurn && containsActionType(legal, ActionEndTurn) {
+			action = Action{Type: ActionEndTurn, PlayerID: CurrentPlayer(game).ID}
+		}
+
+		var before []int
+		if action.Type == ActionRoll {
+			before = handSizes(game)
+		}
+		applyAction(game, action, rng)
+		if action.Type == ActionRoll && game.LastRoll != 7 {
+			for seat, size := range handSizes(game) {
+				result.ResourcesIn[seat] += size - before[seat]
+			}
+		}
+
+		actionsThisTurn++
+		if action.Type == ActionEndTurn {
+			actionsThisTurn = 0
+		}
+	}
+
+	result.Turns = game.TurnCount
+	for seat, player := range game.Players {
+		result.Points[seat] = TotalVictoryPoints(player)
+		if player.ID == game.WinnerID {
+			result.WinnerSeat = seat
+		}
+	}
+	return result, nil
+}
+
+func handSizes(game *CatanGame) []int {
+	sizes := make([]int, len(game.Players))
+	for seat, player := range game.Players {
+		sizes[seat] = handSize(player)
+	}
+	return sizes
+}
+
+func containsActionType(actions []Action, actionType ActionType) bool {
+	for _, action := range actions {
+		if action.Type == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+// SeatSummary aggregates the results for one seat
+type SeatSummary struct {
+	Seat           int
+	Agent          string
+	Wins           int
+	WinRate        float64
+	FirstGames     int // Games where this seat went first
+	FirstWins      int
+	AvgPoints      float64
+	AvgResourcesIn float64
+}
+
+func (r *SimulationReport) Summaries() []SeatSummary {
+	summaries := make([]SeatSummary, len(r.Config.Agents))
+	for seat, name := range r.Config.Agents {
+		summaries[seat] = SeatSummary{Seat: seat + 1, Agent: name}
+	}
+	for _, result := range r.Results {
+		summaries[result.FirstSeat].FirstGames++
+		if result.WinnerSeat >= 0 {
+			summaries[result.WinnerSeat].Wins++
+			if result.WinnerSeat == result.FirstSeat {
+				summaries[result.FirstSeat].FirstWins++
+			}
+		}
+		for seat := range summaries {
+			summaries[seat].AvgPoints += float64(result.Points[seat])
+			summaries[seat].AvgResourcesIn += float64(result.ResourcesIn[seat])
+		}
+	}
+	if games := float64(len(r.Results)); games > 0 {
+		for seat := range summaries {
+			summaries[seat].WinRate = float64(summaries[seat].Wins) / games
+			summaries[seat].AvgPoints /= games
+			summaries[seat].AvgResourcesIn /= games
+		}
+	}
+	return summaries
+}
+
+// Average turns per game, counting only games that finished
+func (r *SimulationReport) AverageTurns() float64 {
+	total, finished := 0, 0
+	for _, result := range r.Results {
+		if result.WinnerSeat >= 0 {
+			total += result.Turns
+			finished++
+		}
+	}
+	if finished == 0 {
+		return 0
+	}
+	return float64(total) / float64(finished)
+}
+
+// FirstPlayerWinRate is how often whoever went first won. With fair seats this
+// would be one over the number of players.
+func (r *SimulationReport) FirstPlayerWinRate() float64 {
+	wins := 0
+	for _, result := range r.Results {
+		if result.WinnerSeat >= 0 && result.WinnerSeat == result.FirstSeat {
+			wins++
+		}
+	}
+	if len(r.Results) == 0 {
+		return 0
+	}
+	return float64(wins) / float64(len(r.Results))
+}
+
+// WriteTable prints the summary for people to read
+func (r *SimulationReport) WriteTable(w io.Writer) {
+	unfinished := 0
+	for _, result := range r.Results {
+		if result.WinnerSeat < 0 {
+			unfinished++
+		}
+	}
+
+	fmt.Fprintf(w, "Games: %d (%d hit the %d turn limit)\n", len(r.Results), unfinished, r.Config.MaxTurns)
+	fmt.Fprintf(w, "Average game length: %.1f turns\n", r.AverageTurns())
+	fmt.Fprintf(w, "First player win rate: %.1f%% (fair would be %.1f%%)\n\n",
+		100*r.FirstPlayerWinRate(), 100/float64(len(r.Config.Agents)))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Seat\tAgent\tWins\tWin rate\tFirst\tWins going first\tAvg VP\tAvg resources\t")
+	for _, s := range r.Summaries() {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.1f%%\t%d\t%d\t%.2f\t%.1f\t\n",
+			s.Seat, s.Agent, s.Wins, 100*s.WinRate, s.FirstGames, s.FirstWins, s.AvgPoints, s.AvgResourcesIn)
+	}
+	tw.Flush()
+}
+
+// WriteCSV writes one row per game so results can be analysed elsewhere
+func (r *SimulationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"game", "seed", "first_seat", "winner_seat", "turns"}
+	for seat := range r.Config.Agents {
+		header = append(header, fmt.Sprintf("seat%d_agent", seat+1), fmt.Sprintf("seat%d_vp", seat+1), fmt.Sprintf("seat%d_resources", seat+1))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for i, result := range r.Results {
+		winner := ""
+		if result.WinnerSeat >= 0 {
+			winner = strconv.Itoa(result.WinnerSeat + 1)
+		}
+		row := []string{
+			strconv.Itoa(i + 1),
+			strconv.FormatInt(result.Seed, 10),
+			strconv.Itoa(result.FirstSeat + 1),
+			winner,
+			strconv.Itoa(result.Turns),
+		}
+		for seat, name := range r.Config.Agents {
+			row = append(row, name, strconv.Itoa(result.Points[seat]), strconv.Itoa(result.ResourcesIn[seat]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,64 @@
+package gameplay
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The same seed plays the same games however many workers share them, and
+// the summaries add up to the results
+func TestRunSimulations(t *testing.T) {
+	config := SimulationConfig{Games: 4, Agents: []string{"greedy", "greedy", "random"}, Seed: 1, Workers: 1, MaxTurns: 300}
+	report, err := RunSimulations(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Workers = 3
+	again, err := RunSimulations(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Results, again.Results) {
+		t.Errorf("the same seed played different games:\n%+v\n%+v", report.Results, again.Results)
+	}
+
+	if len(report.Results) != config.Games {
+		t.Fatalf("got %d results for %d games", len(report.Results), config.Games)
+	}
+	finished := 0
+	for i, result := range report.Results {
+		if result.Seed != config.Seed+int64(i) {
+			t.Errorf("game %d was played with seed %d", i+1, result.Seed)
+		}
+		if result.WinnerSeat >= 0 {
+			finished++
+			if result.Points[result.WinnerSeat] < VictoryPointsToWin {
+				t.Errorf("game %d was won by seat %d with %d points", i+1, result.WinnerSeat+1, result.Points[result.WinnerSeat])
+			}
+		}
+	}
+	if finished == 0 {
+		t.Fatal("no game was won")
+	}
+
+	wins, firsts := 0, 0
+	for _, summary := range report.Summaries() {
+		wins += summary.Wins
+		firsts += summary.FirstGames
+		if rate := float64(summary.Wins) / float64(config.Games); summary.WinRate != rate {
+			t.Errorf("seat %d won %d games at a rate of %v", summary.Seat, summary.Wins, summary.WinRate)
+		}
+	}
+	if wins != finished || firsts != config.Games {
+		t.Errorf("the summaries count %d wins and %d first seats, expected %d and %d", wins, firsts, finished, config.Games)
+	}
+	if report.AverageTurns() <= 0 {
+		t.Errorf("finished games took %v turns on average", report.AverageTurns())
+	}
+}
+
+func TestRunSimulationsRejectsNegativeGames(t *testing.T) {
+	if _, err := RunSimulations(SimulationConfig{Games: -1, Agents: []string{"random", "random"}}); err == nil {
+		t.Error("-1 games were accepted")
+	}
+}
@@ -0,0 +1,90 @@
+package gameplay
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// A game some way past setup, played by greedy bots from a fixed seed
+func midGame(b *testing.B) *CatanGame {
+	rng := rand.New(rand.NewSource(7))
+	game := NewSeededCatanGame([]int{1, 2, 3, 4}, 7)
+	BeginSetup(game, game.Players[0])
+	bot := &GreedyBot{Rand: rng}
+	for game.Phase != "finished" && game.TurnCount < 40 {
+		applyAction(game, bot.ChooseAction(game, LegalActions(game)), rng)
+	}
+	if game.Phase == "finished" {
+		b.Fatal("seeded game finished before reaching mid game")
+	}
+	// Roll so the benchmarks see a full list of building moves
+	for !game.HasRolled || game.Phase == "robber" {
+		applyAction(game, LegalActions(game)[0], rng)
+	}
+	return game
+}
+
+func BenchmarkClone(b *testing.B) {
+	game := midGame(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.Clone()
+	}
+}
+
+func BenchmarkLegalActions(b *testing.B) {
+	game := midGame(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		LegalActions(game)
+	}
+}
+
+// Clone plus a 50 move greedy playout, the inner loop of MCTSBot
+func BenchmarkRollout(b *testing.B) {
+	game := midGame(b)
+	rng := rand.New(rand.NewSource(1))
+	bot := &GreedyBot{Rand: rng, Epsilon: 0.2}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state := game.Clone()
+		for depth := 0; depth < 50 && state.Phase != "finished"; depth++ {
+			applyAction(state, bot.ChooseAction(state, LegalActions(state)), rng)
+		}
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	game := midGame(b)
+	clone := game.Clone()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		game.Equal(clone)
+	}
+}
+
+func TestCloneIsEqualAndIndependent(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	game := NewSeededCatanGame([]int{1, 2, 3}, 3)
+	BeginSetup(game, game.Players[0])
+	bot := &GreedyBot{Rand: rng}
+	for game.TurnCount < 10 {
+		applyAction(game, bot.ChooseAction(game, LegalActions(game)), rng)
+	}
+
+	clone := game.Clone()
+	if !game.Equal(clone) {
+		t.Fatal("clone differs from the original")
+	}
+
+	before := game.Clone()
+	for moves := 0; moves < 20 && clone.Phase != "finished"; moves++ {
+		applyAction(clone, bot.ChooseAction(clone, LegalActions(clone)), rng)
+	}
+	if game.Equal(clone) {
+		t.Fatal("the clone did not change")
+	}
+	if !game.Equal(before) {
+		t.Fatal("moves in the clone changed the original")
+	}
+}
@@ -0,0 +1,271 @@
+// tui.go
+
+// The full-screen mode of the CLI game. The game is drawn on the terminal's
+// alternate screen with the board on the left and, beside it, the hand of
+// the player whose turn it is, everyone's card counts and points, and a log
+// of what has happened. The command line is at the bottom. The whole screen
+// is drawn again before every question, so nothing scrolls away.
+//
+// Only ANSI escape codes are used. The terminal's size is read from the
+// COLUMNS and LINES environment variables, as shells set them.
+package gameplay
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	enterAltScreen = "\033[?1049h"
+	leaveAltScreen = "\033[?1049l"
+	clearScreen    = "\033[H\033[2J"
+
+	defaultScreenWidth  = 140
+	defaultScreenHeight = 45
+	panelGap            = 3 // Columns between the board and the panels
+)
+
+var escapeCodes = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// Width of text as it shows on the terminal, leaving out escape codes
+func visibleLength(text string) int {
+	return len([]rune(escapeCodes.ReplaceAllString(text, "")))
+}
+
+// screen is written to like a terminal: what the game says goes in the log
+type screen struct {
+	out           io.Writer
+	game          *CatanGame
+	board         string // Drawn in place of the game's board while choosing
+	hidden        bool   // Only the prompt is drawn, while the terminal changes hands
+	openHands     bool   // Everyone's cards are shown, not just the current player's
+	log           []string
+	partial       string // Text written since the last newline
+	width, height int
+}
+
+func newScreen(out io.Writer) *screen {
+	size := func(name string, fallback int) int {
+		if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+			return n
+		}
+		return fallback
+	}
+	return &screen{out: out, width: size("COLUMNS", defaultScreenWidth), height: size("LINES", defaultScreenHeight)}
+}
+
+func (s *screen) Write(p []byte) (int, error) {
+	lines := strings.Split(s.partial+string(p), "\n")
+	s.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		s.log = append(s.log, strings.TrimRight(line, " "))
+	}
+	return len(p), nil
+}
+
+// Starts full-screen mode if asked for, and points the screen and any
+// script at the game
+func (cg *CLIGame) watch(game *CatanGame) {
+	cg.follow(game)
+	if !cg.FullScreen {
+		return
+	}
+	if cg.screen == nil {
+		out := cg.Output
+		if out == nil {
+			out = os.Stdout
+		}
+		cg.screen = newScreen(out)
+		fmt.Fprint(out, enterAltScreen)
+	}
+	cg.screen.game = game
+	cg.screen.openHands = !cg.HotSeat
+}
+
+// Close leaves full-screen mode, printing the board and the end of the log
+// on the usual screen so the last of the game stays in view. It does
+// nothing outside full-screen mode.
+func (cg *CLIGame) Close() {
+	s := cg.screen
+	if s == nil {
+		return
+	}
+	cg.screen = nil
+	fmt.Fprint(s.out, leaveAltScreen)
+	if s.game != nil {
+		fmt.Fprint(s.out, RenderBoard(s.game, true))
+	}
+	for _, line := range s.log[max(0, len(s.log)-10):] {
+		fmt.Fprintln(s.out, line)
+	}
+}
+
+// How wide menus can be: the width of the log panel in full-screen mode
+func (cg *CLIGame) menuWidth() int {
+	if cg.screen != nil {
+		return cg.screen.panelWidth()
+	}
+	return 80
+}
+
+func (s *screen) boardLines() []string {
+	board := s.board
+	if board == "" {
+		board = RenderBoard(s.game, true)
+	}
+	return strings.Split(strings.TrimRight(board, "\n"), "\n")
+}
+
+func (s *screen) boardWidth() int {
+	width := 0
+	for _, line := range s.boardLines() {
+		width = max(width, visibleLength(line))
+	}
+	return width
+}
+
+func (s *screen) panelWidth() int {
+	return max(20, s.width-s.boardWidth()-panelGap)
+}
+
+// The panels beside the board, top to bottom, filling the lines given
+func (s *screen) panels(lines int) []string {
+	game := s.game
+	player := CurrentPlayer(game)
+	var panel []string
+	heading := func(title string) {
+		panel = append(panel, "== "+title+" "+strings.Repeat("=", max(0, s.panelWidth()-len(title)-4)))
+	}
+
+	heading(fmt.Sprintf("%s's hand", player))
+	var hand strings.Builder
+	printHand(&hand, player)
+	panel = append(panel, strings.Split(strings.TrimRight(hand.String(), "\n"), "\n")[1:]...)
+	panel = append(panel, fmt.Sprintf("%s, %d points", describeCards(player.Resources), TotalVictoryPoints(player)))
+
+	heading("Players")
+	for _, other := range game.Players {
+		marker := "  "
+		if other == player {
+			marker = "> "
+		}
+		line := fmt.Sprintf("%s%s: %d points, %d cards, %d dev cards, %d knights",
+			marker, colorText(playerLabel(other), other), other.VictoryPoints,
+			handSize(other), devCardCount(other), other.KnightsPlayed)
+		if s.openHands && other != player && handSize(other) > 0 {
+			line += " (" + describeCards(other.Resources) + ")"
+		}
+		if other.ID == game.LongestRoadID {
+			line += ", road"
+		}
+		if other.ID == game.LargestArmyID {
+			line += ", army"
+		}
+		panel = append(panel, line)
+	}
+	panel = append(panel, fmt.Sprintf("  Phase: %s, turn %d, last roll %d", game.Phase, game.TurnCount+1, game.LastRoll))
+
+	heading("Log")
+	room := lines - len(panel)
+	log := s.log
+	if s.partial != "" {
+		log = append(log[:len(log):len(log)], s.partial)
+	}
+	// Only the end of the log fits, wrapped to the panel
+	var wrapped []string
+	for i := len(log) - 1; i >= 0 && len(wrapped) < room; i-- {
+		wrapped = append(wrap(log[i], s.panelWidth()), wrapped...)
+	}
+	return append(panel, wrapped[max(0, len(wrapped)-room):]...)
+}
+
+// Breaks text into lines no wider than width, between words where it can
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if line != "" {
+				lines, line = append(lines, line), ""
+			}
+			lines, word = append(lines, word[:width]), word[width:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines, line = append(lines, line), word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func devCardCount(player *Player) int {
+	count := 0
+	for _, n := range player.DevelopmentCards {
+		count += n
+	}
+	return count
+}
+
+// Cuts text down to a width, keeping escape codes and closing any colour
+func clip(text string, width int) string {
+	if visibleLength(text) <= width {
+		return text
+	}
+	var out strings.Builder
+	shown := 0
+	for len(text) > 0 && shown < width {
+		if loc := escapeCodes.FindStringIndex(text); loc != nil && loc[0] == 0 {
+			out.WriteString(text[:loc[1]])
+			text = text[loc[1]:]
+			continue
+		}
+		r := []rune(text)[0]
+		out.WriteRune(r)
+		text = text[len(string(r)):]
+		shown++
+	}
+	return out.String() + resetColor
+}
+
+// Draws the whole screen with the prompt on the bottom line and the cursor
+// after it
+func (s *screen) draw(prompt string) {
+	var frame strings.Builder
+	frame.WriteString(clearScreen)
+	move := func(line, column int) {
+		fmt.Fprintf(&frame, "\033[%d;%dH", line, column)
+	}
+
+	area := s.height - 2 // Leaves a blank line above the prompt
+	if s.hidden {
+		area = 0
+	}
+	for i, line := range s.boardLines() {
+		if i >= area {
+			break
+		}
+		move(i+1, 1)
+		frame.WriteString(clip(line, s.width))
+	}
+	if s.game != nil && !s.hidden {
+		column := s.boardWidth() + panelGap + 1
+		for i, line := range s.panels(area) {
+			move(i+1, column)
+			frame.WriteString(clip(line, s.panelWidth()))
+		}
+	}
+	move(s.height, 1)
+	frame.WriteString(clip(prompt, s.width))
+	fmt.Fprint(s.out, frame.String())
+}
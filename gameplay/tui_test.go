@@ -0,0 +1,89 @@
+package gameplay
+
+import (
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var cursorMove = regexp.MustCompile(`^\x1b\[(\d+);(\d+)H`)
+
+// Plays the last frame written into a grid of lines, following cursor moves
+// and leaving out colours
+func lastFrame(output string, width, height int) []string {
+	frame := output[strings.LastIndex(output, clearScreen)+len(clearScreen):]
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", width))
+	}
+	line, column := 0, 0
+	for len(frame) > 0 {
+		if m := cursorMove.FindStringSubmatch(frame); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			column, _ = strconv.Atoi(m[2])
+			line, column = line-1, column-1
+			frame = frame[len(m[0]):]
+			continue
+		}
+		if loc := escapeCodes.FindStringIndex(frame); loc != nil && loc[0] == 0 {
+			frame = frame[loc[1]:]
+			continue
+		}
+		r := []rune(frame)[0]
+		if line < height && column < width {
+			grid[line][column] = r
+		}
+		column++
+		frame = frame[len(string(r)):]
+	}
+	lines := make([]string, height)
+	for i, row := range grid {
+		lines[i] = strings.TrimRight(string(row), " ")
+	}
+	return lines
+}
+
+func TestFullScreen(t *testing.T) {
+	t.Setenv("COLUMNS", "150")
+	t.Setenv("LINES", "40")
+	game := setUpGame(t)
+	var output strings.Builder
+	cg := &CLIGame{
+		Input:      strings.NewReader("fly\nh\n"),
+		Output:     &output,
+		SavePath:   filepath.Join(t.TempDir(), "save.json"),
+		FullScreen: true,
+	}
+	if err := cg.Play(game); !errors.Is(err, ErrInputClosed) {
+		t.Fatalf("got %v, expected the input to run out", err)
+	}
+	if !strings.HasPrefix(output.String(), enterAltScreen) {
+		t.Error("the alternate screen was not used")
+	}
+
+	lines := lastFrame(output.String(), 150, 40)
+	screen := strings.Join(lines, "\n")
+	hand := describeCards(game.Players[0].Resources) + ", 2 points"
+	for _, expected := range []string{"== Player 1's hand", hand, "== Players", "== Log", "> Player 1:", `"fly" is not a command`} {
+		if !strings.Contains(screen, expected) {
+			t.Errorf("%q is not on the screen:\n%s", expected, screen)
+		}
+	}
+	if !strings.HasPrefix(lines[39], "Player 1, roll when ready>") {
+		t.Errorf("the bottom line is %q, expected the prompt", lines[39])
+	}
+	// The board and the panels sit side by side
+	for i, line := range lines {
+		if strings.Contains(line, "== Player 1's hand") && (i != 0 || !strings.Contains(line, "3:1")) {
+			t.Errorf("the hand panel is on line %d: %q", i, line)
+		}
+	}
+
+	cg.Close()
+	if after := output.String()[strings.LastIndex(output.String(), leaveAltScreen):]; !strings.Contains(after, "Input ended") {
+		t.Error("the end of the log was not printed after leaving the full screen")
+	}
+}
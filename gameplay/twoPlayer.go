@@ -0,0 +1,219 @@
+// twoPlayer.go
+
+// The official 2-player variant. Two neutral players share the board with
+// the real ones: they never take a turn, roll or hold cards, but their pieces
+// block like anyone else's.
+//   - Setup: after the usual snake placement each neutral gets two settlements
+//     with roads, placed by the real players taking turns.
+//   - Building: whenever a player builds a road or settlement they also place
+//     one for a neutral player of their choice, for free. If no neutral can
+//     take a settlement they place a neutral road instead.
+//   - Rolling: the dice are rolled twice each turn, and the second roll must
+//     differ from the first, so the board produces about as much as it would
+//     with more players.
+//   - Trade tokens: each player starts with 5. Building a settlement or city
+//     next to the desert earns 2, a settlement on the coast 1, and playing a
+//     knight 1. After rolling a player may spend them, 1 token if they are
+//     not ahead on points and 2 if they are, to force a trade once a turn
+//     (take 2 random cards from the opponent, then give 2 of their choice
+//     back) or to return the robber to the desert.
+package gameplay
+
+import "math/rand"
+
+const (
+	neutralPlayerCount  = 2
+	startingTradeTokens = 5
+)
+
+// Neutral players get the IDs after the highest real player's ID, and the
+// real players their trade tokens
+func addNeutrals(game *CatanGame) {
+	highest := 0
+	for _, player := range game.Players {
+		highest = max(highest, player.ID)
+	}
+	for _, player := range game.Players {
+		player.TradeTokens = startingTradeTokens
+	}
+	for i := 1; i <= neutralPlayerCount; i++ {
+		game.Neutrals = append(game.Neutrals, &Player{
+			ID:               highest + i,
+			Resources:        make(map[string]int),
+			DevelopmentCards: make(map[string]int),
+			BoughtThisTurn:   make(map[string]int),
+			Neutral:          true,
+		})
+	}
+}
+
+// Setup entries after the snake are neutral placements: the real players in
+// SetupOrder alternate, placing twice for the first neutral then twice for
+// the second
+func setupNeutralOrder(game *CatanGame, order []int) []int {
+	for i := 0; i < 2*len(game.Neutrals); i++ {
+		order = append(order, order[i%len(game.Players)])
+	}
+	return order
+}
+
+// The player receiving the piece placed at the current setup step
+func setupOwner(game *CatanGame) *Player {
+	snake := 2 * len(game.Players)
+	if game.SetupIndex >= snake {
+		return game.Neutrals[(game.SetupIndex-snake)/2]
+	}
+	return GetPlayerByID(game, game.SetupOrder[game.SetupIndex])
+}
+
+// GetNeutralByID finds a neutral player, nil if there is none with that ID
+func GetNeutralByID(game *CatanGame, playerID int) *Player {
+	for _, neutral := range game.Neutrals {
+		if neutral.ID == playerID {
+			return neutral
+		}
+	}
+	return nil
+}
+
+// Starts the neutral build owed for the piece just built, if any neutral can
+// take one
+func oweNeutralBuild(game *CatanGame, piece string) {
+	if len(game.Neutrals) == 0 || game.Phase != "main" {
+		return
+	}
+	game.NeutralBuild = piece
+	if len(neutralActions(game, CurrentPlayer(game))) == 0 {
+		game.NeutralBuild = ""
+		return
+	}
+	game.Phase = "neutral"
+}
+
+// Placements the player can make for the neutral players. A settlement is
+// owed as a road when no neutral has anywhere to settle.
+func neutralActions(game *CatanGame, player *Player) []Action {
+	var actions []Action
+	if game.NeutralBuild == "settlement" {
+		for _, neutral := range game.Neutrals {
+			for _, vertexID := range ComputeValidSettlementPlacements(game, neutral) {
+				actions = append(actions, Action{Type: ActionNeutralSettlement, PlayerID: player.ID, NeutralID: neutral.ID, VertexID: vertexID})
+			}
+		}
+		if len(actions) > 0 {
+			return actions
+		}
+	}
+	for _, neutral := range game.Neutrals {
+		for _, road := range ComputeValidRoadPlacements(game, neutral) {
+			actions = append(actions, Action{Type: ActionNeutralRoad, PlayerID: player.ID, NeutralID: neutral.ID, VertexID: road[0], VertexID2: road[1]})
+		}
+	}
+	return actions
+}
+
+// The other real player of a 2-player game, nil in any other game
+func tokenOpponent(game *CatanGame, player *Player) *Player {
+	if len(game.Neutrals) == 0 {
+		return nil
+	}
+	for _, other := range game.Players {
+		if other != player {
+			return other
+		}
+	}
+	return nil
+}
+
+// Trade tokens a forced trade or returning the robber costs: 1 for a player
+// not ahead on points, 2 for one who is
+func tradeTokenCost(game *CatanGame, player *Player) int {
+	if opponent := tokenOpponent(game, player); opponent != nil && player.VictoryPoints > opponent.VictoryPoints {
+		return 2
+	}
+	return 1
+}
+
+// The first desert tile, nil if the board has none
+func desertTile(game *CatanGame) *Tile {
+	for _, tile := range game.Board.Tiles {
+		if tile.Resource == "D" {
+			return tile
+		}
+	}
+	return nil
+}
+
+// Forced trades and returning the robber, if the player has the tokens
+func tradeTokenActions(game *CatanGame, player *Player) []Action {
+	opponent := tokenOpponent(game, player)
+	if opponent == nil || player.TradeTokens < tradeTokenCost(game, player) {
+		return nil
+	}
+	var actions []Action
+	if !game.ForcedTrade && handSize(opponent) > 0 {
+		actions = append(actions, Action{Type: ActionForcedTrade, PlayerID: player.ID, VictimID: opponent.ID})
+	}
+	if desert := desertTile(game); desert != nil && game.Board.RobberPosition != desert.ID {
+		actions = append(actions, Action{Type: ActionReturnRobber, PlayerID: player.ID, TileID: desert.ID})
+	}
+	return actions
+}
+
+// The cards the player can give back after a forced trade
+func giveBackActions(game *CatanGame, player *Player) []Action {
+	var actions []Action
+	opponent := tokenOpponent(game, player)
+	for _, resource := range ResourceTypes {
+		if player.Resources[resource] > 0 {
+			actions = append(actions, Action{Type: ActionGiveBack, PlayerID: player.ID, VictimID: opponent.ID, Give: resource})
+		}
+	}
+	return actions
+}
+
+// Pays for a forced trade and takes up to 2 random cards from the opponent,
+// leaving the player to give as many back in the "forced" phase
+func forcedTrade(game *CatanGame, player *Player, rng *rand.Rand) {
+	opponent := tokenOpponent(game, player)
+	player.TradeTokens -= tradeTokenCost(game, player)
+	game.ForcedTrade = true
+	for game.GiveBack < 2 && handSize(opponent) > 0 {
+		stealRandomResource(opponent, player, rng)
+		game.GiveBack++
+	}
+	game.Phase = "forced"
+}
+
+func giveBack(game *CatanGame, player *Player, resource string) {
+	player.Resources[resource]--
+	tokenOpponent(game, player).Resources[resource]++
+	game.GiveBack--
+	if game.GiveBack == 0 {
+		game.Phase = "main"
+	}
+}
+
+// Trade tokens for a settlement or city next to the desert, a settlement on
+// the coast, or a knight played
+func earnTradeTokens(game *CatanGame, player *Player, vertexID int, piece string) {
+	if len(game.Neutrals) == 0 {
+		return
+	}
+	if piece == "knight" {
+		player.TradeTokens++
+		return
+	}
+	land := 0
+	for _, tile := range vertexTiles(game, vertexID) {
+		if tile.Resource == "D" {
+			player.TradeTokens += 2
+		}
+		if tile.Resource != Sea {
+			land++
+		}
+	}
+	if piece == "settlement" && land < 3 {
+		player.TradeTokens++
+	}
+}
@@ -0,0 +1,165 @@
+package gameplay
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestTwoPlayerVariant(t *testing.T) {
+	game := NewSeededCatanGame([]int{1, 2}, 1)
+	if len(game.Neutrals) != 2 || game.Neutrals[0].ID != 3 || game.Neutrals[1].ID != 4 {
+		t.Fatalf("expected neutral players 3 and 4, got %d neutrals", len(game.Neutrals))
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	BeginSetup(game, game.Players[0])
+	for game.Phase == "setup" {
+		applyAction(game, LegalActions(game)[0], rng)
+	}
+	for _, neutral := range game.Neutrals {
+		if countBuildings(game, neutral, 1) != 2 || countRoads(game, neutral) != 2 || handSize(neutral) != 0 {
+			t.Errorf("neutral %d has %d settlements, %d roads and %d cards, expected 2, 2 and 0",
+				neutral.ID, countBuildings(game, neutral, 1), countRoads(game, neutral), handSize(neutral))
+		}
+	}
+
+	// Two rolls, the second different from the first
+	applyAction(game, Action{Type: ActionRoll, PlayerID: 1}, rng)
+	for game.Phase == "robber" {
+		applyAction(game, LegalActions(game)[0], rng)
+	}
+	first := game.LastRoll
+	if game.HasRolled || !containsActionType(LegalActions(game), ActionRoll) {
+		t.Fatal("expected a second roll")
+	}
+	applyAction(game, Action{Type: ActionRoll, PlayerID: 1}, rng)
+	if !game.HasRolled || game.LastRoll == first {
+		t.Errorf("second roll %d after %d", game.LastRoll, first)
+	}
+	for game.Phase == "robber" {
+		applyAction(game, LegalActions(game)[0], rng)
+	}
+
+	// Building a road owes a neutral piece
+	player := CurrentPlayer(game)
+	player.Resources["B"], player.Resources["L"] = 1, 1
+	road := ComputeValidRoadPlacements(game, player)[0]
+	applyAction(game, Action{Type: ActionBuildRoad, PlayerID: player.ID, VertexID: road[0], VertexID2: road[1]}, rng)
+	legal := LegalActions(game)
+	if game.Phase != "neutral" || len(legal) == 0 || legal[0].Type != ActionNeutralRoad {
+		t.Fatalf("expected neutral roads to place, got %s phase with %v", game.Phase, legal)
+	}
+	applyAction(game, legal[0], rng)
+	if game.Phase != "main" || countRoads(game, GetNeutralByID(game, legal[0].NeutralID)) != 3 {
+		t.Errorf("neutral road not placed")
+	}
+}
+
+// A 2-player game after setup, the first player to move having rolled
+func twoPlayerGame(t *testing.T) *CatanGame {
+	t.Helper()
+	game := NewSeededCatanGame([]int{1, 2}, 1)
+	BeginSetup(game, game.Players[0])
+	for game.Phase == "setup" {
+		applyAction(game, LegalActions(game)[0], nil)
+	}
+	game.HasRolled = true
+	return game
+}
+
+func TestTradeTokens(t *testing.T) {
+	game := twoPlayerGame(t)
+	player, opponent := CurrentPlayer(game), tokenOpponent(game, CurrentPlayer(game))
+	if player.TradeTokens != startingTradeTokens || opponent.TradeTokens != startingTradeTokens || game.Neutrals[0].TradeTokens != 0 {
+		t.Fatalf("players start with %d and %d tokens, expected %d", player.TradeTokens, opponent.TradeTokens, startingTradeTokens)
+	}
+
+	// A forced trade takes 2 cards, then 2 are given back
+	player.Resources = map[string]int{"B": 1}
+	opponent.Resources = map[string]int{"W": 2, "O": 1}
+	opponent.VictoryPoints = player.VictoryPoints
+	force := Action{Type: ActionForcedTrade, PlayerID: player.ID, VictimID: opponent.ID}
+	if err := ApplyAction(game, force, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	if player.TradeTokens != startingTradeTokens-1 || handSize(player) != 3 || game.Phase != "forced" {
+		t.Fatalf("after forcing a trade player has %d tokens and %v in the %s phase", player.TradeTokens, player.Resources, game.Phase)
+	}
+	for game.Phase == "forced" {
+		legal := LegalActions(game)
+		if !containsActionType(legal, ActionGiveBack) || containsActionType(legal, ActionEndTurn) {
+			t.Fatalf("expected only cards to give back, got %v", legal)
+		}
+		applyAction(game, legal[0], nil)
+	}
+	if handSize(player) != 1 || handSize(opponent) != 3 {
+		t.Errorf("hands of %d and %d after the trade, expected 1 and 3", handSize(player), handSize(opponent))
+	}
+	if containsActionType(LegalActions(game), ActionForcedTrade) {
+		t.Error("a second trade could be forced in the same turn")
+	}
+
+	// The leader pays 2 to return the robber
+	desert := desertTile(game)
+	game.Board.RobberPosition = desert.ID%TileID(len(game.Board.Tiles)) + 1
+	player.VictoryPoints = opponent.VictoryPoints + 1
+	if err := ApplyAction(game, Action{Type: ActionReturnRobber, PlayerID: player.ID, TileID: desert.ID}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if game.Board.RobberPosition != desert.ID || player.TradeTokens != startingTradeTokens-3 {
+		t.Errorf("the robber is on %d and player has %d tokens", game.Board.RobberPosition, player.TradeTokens)
+	}
+
+	player.TradeTokens = 1
+	game.Board.RobberPosition = desert.ID%TileID(len(game.Board.Tiles)) + 1
+	if containsActionType(LegalActions(game), ActionReturnRobber) {
+		t.Error("the leader returned the robber with 1 token")
+	}
+}
+
+func TestEarnTradeTokens(t *testing.T) {
+	game := twoPlayerGame(t)
+	player := CurrentPlayer(game)
+	desert := desertTile(game)
+	for vertexID := 1; vertexID <= game.Board.Graph.VertexCount(); vertexID++ {
+		byDesert, land := false, 0
+		for _, tile := range vertexTiles(game, vertexID) {
+			byDesert = byDesert || tile == desert
+			if tile.Resource != Sea {
+				land++
+			}
+		}
+		expected := map[string]int{"settlement": 0, "city": 0}
+		if byDesert {
+			expected["settlement"], expected["city"] = 2, 2
+		}
+		if land < 3 {
+			expected["settlement"]++
+		}
+		for piece, tokens := range expected {
+			player.TradeTokens = 0
+			earnTradeTokens(game, player, vertexID, piece)
+			if player.TradeTokens != tokens {
+				t.Errorf("a %s on vertex %d earned %d tokens, expected %d", piece, vertexID, player.TradeTokens, tokens)
+			}
+		}
+	}
+
+	player.TradeTokens = 0
+	earnTradeTokens(game, player, 0, "knight")
+	if player.TradeTokens != 1 {
+		t.Errorf("a knight earned %d tokens, expected 1", player.TradeTokens)
+	}
+
+	game = setUpGame(t)
+	game.HasRolled = true
+	command, args := matchCommand([]string{"force", "trade"})
+	action, err := parseAction(game, command.names[0], args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := explainIllegal(game, action); err == nil || !strings.Contains(err.Error(), "2-player game") {
+		t.Errorf("a forced trade with 3 players was explained as %v", err)
+	}
+}